@@ -0,0 +1,129 @@
+package selectr
+
+import (
+	"fmt"
+	"strconv"
+	"unicode/utf8"
+
+	"github.com/0xch4z/selectr/internal/parser/ast"
+	"github.com/0xch4z/selectr/internal/parser/token"
+)
+
+// Root returns the empty selector, which selects the whole document. It is
+// the start of building a selector step by step, without formatting a
+// key-path:
+//
+//	sel := Root().Key("accounts").Index(0).Key("content-type")
+//	sel.String() // => ".accounts[0]['content-type']"
+//
+// Keys are taken as-is, however odd, so the selector selects exactly the
+// keys given and round-trips through String and Parse. Indices that cannot
+// be selected are recorded as an error, returned by Resolve, Expand, Set
+// and Delete.
+func Root() *Selector {
+	return &Selector{}
+}
+
+// Key returns a new selector selecting the entry of the key on the value
+// the selector selects.
+func (s *Selector) Key(key string) *Selector {
+	return s.build(key)
+}
+
+// Index returns a new selector selecting the element at the index on the
+// value the selector selects. A negative index is not appended; the
+// selector records an IndexError instead.
+func (s *Selector) Index(i int) *Selector {
+	if i < 0 && s.err == nil {
+		sel := selectorFromKeys(s.keys())
+		sel.err = ResolveError{
+			Code: "IndexError",
+			Msg:  fmt.Sprintf("negative index %d", i),
+			Pos:  utf8.RuneCountInString(sel.String()),
+			Path: sel.String(),
+		}
+		return sel
+	}
+	return s.build(i)
+}
+
+// Wildcard returns a new selector selecting every entry or element of the
+// value the selector selects.
+func (s *Selector) Wildcard() *Selector {
+	return s.build(wildcard{})
+}
+
+// build returns a new selector with the key appended, keeping the error
+// recorded while building the selector, if any.
+func (s *Selector) build(key interface{}) *Selector {
+	if s.err != nil {
+		return &Selector{tree: s.tree, err: s.err}
+	}
+	return selectorFromKeys(append(s.keys(), key))
+}
+
+// selectorFromKeys returns the selector of the keys and indices, as parsed
+// from its canonical key-path.
+func selectorFromKeys(keys []interface{}) *Selector {
+	var head, tail *TraversalTreeNode
+	pos := 0
+	for _, key := range keys {
+		curr := &TraversalTreeNode{Resolver: newStepResolver(key, pos)}
+		pos += utf8.RuneCountInString(segmentFor(key))
+
+		if head == nil {
+			head = curr
+		} else {
+			tail.Child = curr
+			curr.Parent = tail
+		}
+		tail = curr
+	}
+	return &Selector{tree: head}
+}
+
+// newStepResolver returns the resolver of the key or index with the
+// expression of its canonical segment at the position.
+func newStepResolver(key interface{}, pos int) Resolver {
+	node := func(tok token.Token, lit string, pos int) *ast.Node {
+		return &ast.Node{Tok: tok, Lit: lit, StartPos: pos, EndPos: pos + len(lit)}
+	}
+	indexExpr := func(index ast.LitExpr, lit string) *ast.IndexExpr {
+		return &ast.IndexExpr{
+			LBracket: node(token.LBracket, "[", pos),
+			Index:    index,
+			RBracket: node(token.RBracket, "]", pos+1+utf8.RuneCountInString(lit)),
+		}
+	}
+
+	switch k := key.(type) {
+	case int:
+		lit := strconv.Itoa(k)
+		return &SliceElementResolver{
+			Index: k,
+			Expr:  indexExpr(&ast.IntLit{Node: node(token.Int, lit, pos+1)}, lit),
+		}
+
+	case wildcard:
+		return &WildcardResolver{
+			Expr: indexExpr(&ast.WildcardLit{Node: node(token.Star, "*", pos+1)}, "*"),
+		}
+	}
+
+	k := key.(string)
+	if isIdent(k) {
+		return &MapEntryResolver{
+			Key: k,
+			Expr: &ast.AttrExpr{
+				Dot:  node(token.Dot, ".", pos),
+				Attr: node(token.Ident, k, pos+1),
+			},
+		}
+	}
+
+	lit := quoteKey(k)
+	return &MapEntryResolver{
+		Key:  k,
+		Expr: indexExpr(&ast.StringLit{Node: node(token.String, lit, pos+1)}, lit),
+	}
+}
@@ -0,0 +1,126 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type builderTestFixture struct {
+	sel      *Selector
+	expected string
+}
+
+func runBuilderTest(t *testing.T, fixture builderTestFixture) {
+	t.Helper()
+
+	if s := fixture.sel.String(); s != fixture.expected {
+		t.Errorf("expected selector `%s` but got `%s`", fixture.expected, s)
+	}
+
+	// the selector round-trips through Parse.
+	parsed, err := Parse(fixture.sel.String())
+	if err != nil {
+		t.Errorf("`%s` could not be parsed: %s", fixture.sel, err)
+		return
+	}
+
+	var built, reparsed []Resolver
+	for _, n := range fixture.sel.Steps() {
+		built = append(built, n.Resolver)
+	}
+	for _, n := range parsed.Steps() {
+		reparsed = append(reparsed, n.Resolver)
+	}
+	if diff := cmp.Diff(reparsed, built); diff != "" {
+		t.Errorf("`%s` was not built as parsed:\n%s", fixture.sel, diff)
+	}
+}
+
+func TestBuilder(t *testing.T) {
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root(),
+		expected: "",
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Key("accounts").Index(0).Key("content-type"),
+		expected: ".accounts[0]['content-type']",
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Key("users").Wildcard().Key("email"),
+		expected: ".users[*].email",
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Index(12).Key("a.b").Key(""),
+		expected: "[12]['a.b']['']",
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Key(`it's "quoted"`).Key(`back\slash`).Key("new\nline"),
+		expected: `['it\'s "quoted"']['back\\slash']['new\nline']`,
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Key("été").Key("*").Key("[0]"),
+		expected: "['été']['*']['[0]']",
+	})
+
+	runBuilderTest(t, builderTestFixture{
+		sel:      Root().Key("a\x00b").Key("\x1b"),
+		expected: `['a\0b']['\e']`,
+	})
+}
+
+func TestBuilder_resolve(t *testing.T) {
+	doc := map[string]interface{}{
+		"a.b": map[string]interface{}{
+			"it's": []interface{}{"x", "y"},
+		},
+	}
+
+	v, err := Root().Key("a.b").Key("it's").Index(1).Resolve(doc)
+	if err != nil || v != "y" {
+		t.Errorf("unexpected value: %v (%v)", v, err)
+	}
+
+	// errors are positioned at the steps of the canonical key-path.
+	_, err = Root().Key("a.b").Key("missing").Resolve(doc, Strict())
+	if e, ok := err.(ResolveError); !ok || e.Pos != 7 || e.Path != "['a.b']" {
+		t.Errorf("unexpected error: %#v", err)
+	}
+}
+
+func TestBuilder_immutable(t *testing.T) {
+	base := Root().Key("users")
+	a, b := base.Index(0), base.Index(1)
+
+	if base.String() != ".users" || a.String() != ".users[0]" || b.String() != ".users[1]" {
+		t.Errorf("building a selector modified another: %s, %s, %s", base, a, b)
+	}
+}
+
+func TestBuilder_negativeIndex(t *testing.T) {
+	// the error is recorded and returned by the terminal calls.
+	sel := Root().Key("a").Index(-1).Key("b")
+	doc := map[string]interface{}{"a": []interface{}{1}}
+
+	expected := ResolveError{Code: "IndexError", Msg: "negative index -1", Pos: 2, Path: ".a"}
+	if _, err := sel.Resolve(doc); cmp.Diff(expected, err) != "" {
+		t.Errorf("expected %v resolving but got %v", expected, err)
+	}
+	if _, err := sel.Expand(doc); cmp.Diff(expected, err) != "" {
+		t.Errorf("expected %v expanding but got %v", expected, err)
+	}
+	if _, err := sel.Set(doc, 1); cmp.Diff(expected, err) != "" {
+		t.Errorf("expected %v setting but got %v", expected, err)
+	}
+	if _, err := sel.Delete(doc); cmp.Diff(expected, err) != "" {
+		t.Errorf("expected %v deleting but got %v", expected, err)
+	}
+	if diff := cmp.Diff(map[string]interface{}{"a": []interface{}{1}}, doc); diff != "" {
+		t.Errorf("the document was modified:\n%s", diff)
+	}
+}
@@ -0,0 +1,264 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// Format represents a document format.
+type Format string
+
+// Supported document formats.
+const (
+	FormatJSON Format = "json"
+	FormatYAML Format = "yaml"
+	FormatTOML Format = "toml"
+)
+
+// parseFormat parses a document format from a flag value.
+func parseFormat(s string) (Format, error) {
+	switch strings.ToLower(s) {
+	case "json":
+		return FormatJSON, nil
+	case "yaml", "yml":
+		return FormatYAML, nil
+	case "toml":
+		return FormatTOML, nil
+	}
+	return "", fmt.Errorf("unknown format '%s'; expected one of json, yaml or toml", s)
+}
+
+// detectFormat detects the format of a document from the file name. If the
+// file has no known extension, the content is sniffed: JSON documents begin
+// with an object or array, anything else is treated as YAML.
+func detectFormat(name string, data []byte) Format {
+	switch strings.ToLower(filepath.Ext(name)) {
+	case ".json":
+		return FormatJSON
+	case ".yaml", ".yml":
+		return FormatYAML
+	case ".toml":
+		return FormatTOML
+	}
+
+	if trimmed := bytes.TrimSpace(data); len(trimmed) != 0 {
+		switch trimmed[0] {
+		case '{', '[':
+			return FormatJSON
+		}
+	}
+	return FormatYAML
+}
+
+// decode decodes the document in the given format.
+func decode(data []byte, format Format) (interface{}, error) {
+	var v interface{}
+
+	switch format {
+	case FormatJSON:
+		// numbers are decoded as json.Number so they are printed exactly
+		// as they were read.
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&v); err != nil {
+			return nil, err
+		}
+
+	case FormatYAML:
+		if err := yaml.Unmarshal(data, &v); err != nil {
+			return nil, err
+		}
+
+	case FormatTOML:
+		var m map[string]interface{}
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		v = m
+	}
+
+	return normalize(v), nil
+}
+
+// normalize converts the concrete container types produced by decoders into
+// the generic containers supported by selectr. TOML arrays of tables, for
+// instance, are decoded as `[]map[string]interface{}`.
+func normalize(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			v[k] = normalize(val)
+		}
+		return v
+
+	case []interface{}:
+		for i, val := range v {
+			v[i] = normalize(val)
+		}
+		return v
+
+	case []map[string]interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = normalize(val)
+		}
+		return s
+	}
+	return v
+}
+
+// jsonable converts the value into one that can be encoded as JSON. Maps
+// keyed by interface{} are converted to maps keyed by strings.
+func jsonable(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = jsonable(val)
+		}
+		return m
+
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[fmt.Sprint(k)] = jsonable(val)
+		}
+		return m
+
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = jsonable(val)
+		}
+		return s
+	}
+	return v
+}
+
+// OutputMode represents how resolved values are printed.
+type OutputMode string
+
+// Supported output modes.
+const (
+	OutputRaw  OutputMode = "raw"
+	OutputJSON OutputMode = "json"
+	OutputYAML OutputMode = "yaml"
+)
+
+// parseOutputMode parses an output mode from a flag value.
+func parseOutputMode(s string) (OutputMode, error) {
+	switch strings.ToLower(s) {
+	case "raw":
+		return OutputRaw, nil
+	case "json":
+		return OutputJSON, nil
+	case "yaml", "yml":
+		return OutputYAML, nil
+	}
+	return "", fmt.Errorf("unknown output mode '%s'; expected one of raw, json or yaml", s)
+}
+
+// render renders the value in the output mode. Output always ends with a
+// newline.
+func render(v interface{}, mode OutputMode) ([]byte, error) {
+	switch mode {
+	case OutputRaw:
+		// scalars are printed as-is; strings are not quoted so they can be
+		// consumed by other programs. Containers are printed as JSON.
+		switch v := v.(type) {
+		case string:
+			return []byte(v + "\n"), nil
+		case time.Time:
+			return []byte(v.Format(time.RFC3339Nano) + "\n"), nil
+		case nil:
+			return []byte("null\n"), nil
+		case float64:
+			return []byte(formatFloat(v, 64) + "\n"), nil
+		case float32:
+			return []byte(formatFloat(float64(v), 32) + "\n"), nil
+		case json.Number:
+			return []byte(formatNumber(v) + "\n"), nil
+		case map[string]interface{}, map[interface{}]interface{}, []interface{}:
+			return render(v, OutputJSON)
+		}
+		return []byte(fmt.Sprintln(v)), nil
+
+	case OutputJSON:
+		b, err := json.MarshalIndent(jsonable(v), "", "  ")
+		if err != nil {
+			return nil, err
+		}
+		return append(b, '\n'), nil
+
+	case OutputYAML:
+		return yaml.Marshal(yamlable(v))
+	}
+	return nil, fmt.Errorf("unknown output mode '%s'", mode)
+}
+
+// formatFloat formats the float without an exponent, so integral values
+// such as 1000000 are not printed as 1e+06.
+func formatFloat(f float64, bitSize int) string {
+	return strconv.FormatFloat(f, 'f', -1, bitSize)
+}
+
+// formatNumber formats the number as it was written, unless it was written
+// with an exponent.
+func formatNumber(n json.Number) string {
+	if strings.ContainsAny(string(n), "eE") {
+		if f, err := n.Float64(); err == nil {
+			return formatFloat(f, 64)
+		}
+	}
+	return n.String()
+}
+
+// yamlable converts the value into one that can be encoded as YAML.
+// json.Numbers, which yaml.v3 encodes as strings, are converted to number
+// scalars.
+func yamlable(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = yamlable(val)
+		}
+		return m
+
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = yamlable(val)
+		}
+		return m
+
+	case []interface{}:
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = yamlable(val)
+		}
+		return s
+
+	case json.Number:
+		tag := "!!float"
+		if strings.Trim(string(v), "-0123456789") == "" {
+			tag = "!!int"
+		}
+		return &yaml.Node{Kind: yaml.ScalarNode, Tag: tag, Value: v.String()}
+	}
+	return v
+}
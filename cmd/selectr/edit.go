@@ -0,0 +1,254 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// editableDocument represents a document that is modified and written back
+// in its original format.
+type editableDocument struct {
+	format Format
+
+	// root is the decoded document. YAML documents are decoded as a
+	// *yaml.Node so comments and key order are preserved.
+	root interface{}
+
+	// indent is the indentation detected in the source document. JSON
+	// documents are written compact if it is empty.
+	indent string
+}
+
+// decodeEditable decodes the document so it can be modified and encoded
+// again in the same format.
+func decodeEditable(data []byte, format Format) (*editableDocument, error) {
+	doc := &editableDocument{
+		format: format,
+		indent: detectIndent(data),
+	}
+
+	switch format {
+	case FormatJSON:
+		if !bytes.Contains(bytes.TrimSpace(data), []byte("\n")) {
+			// documents on a single line are written back compact.
+			doc.indent = ""
+		}
+
+		// numbers are decoded as json.Number so they are written back
+		// exactly as they were read.
+		d := json.NewDecoder(bytes.NewReader(data))
+		d.UseNumber()
+		if err := d.Decode(&doc.root); err != nil {
+			return nil, err
+		}
+
+	case FormatYAML:
+		var n yaml.Node
+		if err := yaml.Unmarshal(data, &n); err != nil {
+			return nil, err
+		}
+		if n.Kind == 0 {
+			// the document is empty.
+			n.Kind = yaml.DocumentNode
+		}
+		doc.root = &n
+
+	case FormatTOML:
+		var m map[string]interface{}
+		if err := toml.Unmarshal(data, &m); err != nil {
+			return nil, err
+		}
+		doc.root = normalize(m)
+	}
+
+	return doc, nil
+}
+
+// encode encodes the document in its original format.
+func (d *editableDocument) encode() ([]byte, error) {
+	var buf bytes.Buffer
+
+	switch d.format {
+	case FormatJSON:
+		e := json.NewEncoder(&buf)
+		e.SetEscapeHTML(false)
+		e.SetIndent("", d.indent)
+		if err := e.Encode(jsonable(d.root)); err != nil {
+			return nil, err
+		}
+
+	case FormatYAML:
+		e := yaml.NewEncoder(&buf)
+		if indent := len(d.indent); indent >= 2 {
+			e.SetIndent(indent)
+		} else {
+			e.SetIndent(2)
+		}
+		if n, ok := d.root.(*yaml.Node); ok {
+			untagMergeKeys(n)
+		}
+		if err := e.Encode(d.root); err != nil {
+			return nil, err
+		}
+		if err := e.Close(); err != nil {
+			return nil, err
+		}
+
+	case FormatTOML:
+		e := toml.NewEncoder(&buf)
+		e.Indent = ""
+		if err := e.Encode(d.root); err != nil {
+			return nil, err
+		}
+	}
+
+	return buf.Bytes(), nil
+}
+
+// untagMergeKeys clears the tag of the merge keys (`<<`) in the node tree.
+// yaml.v3 resolves them to the !!merge tag when decoding, but writes the
+// tag out explicitly when encoding, as in `!!merge <<: *base`.
+func untagMergeKeys(n *yaml.Node) {
+	if n.Kind == yaml.MappingNode {
+		for i := 0; i < len(n.Content); i += 2 {
+			if k := n.Content[i]; k.Kind == yaml.ScalarNode && k.Tag == "!!merge" && k.Style&yaml.TaggedStyle == 0 {
+				k.Tag = ""
+			}
+		}
+	}
+	for _, c := range n.Content {
+		untagMergeKeys(c)
+	}
+}
+
+// detectIndent returns the indentation of the first indented line of the
+// document. Two spaces are returned if no line is indented.
+func detectIndent(data []byte) string {
+	for _, line := range strings.Split(string(data), "\n") {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" || len(trimmed) == len(line) {
+			continue
+		}
+		return line[:len(line)-len(trimmed)]
+	}
+	return "  "
+}
+
+// parseValue parses a value given on the command line. Values are parsed as
+// YAML (and therefore JSON) scalars or collections, e.g. `3`, `true` or
+// `{"a": 1}`; anything that cannot be parsed is used as a string. If literal
+// is true, the value is always used as a string.
+func parseValue(s string, literal bool) interface{} {
+	if literal {
+		return s
+	}
+
+	var v interface{}
+	if err := yaml.Unmarshal([]byte(s), &v); err != nil || v == nil && strings.TrimSpace(s) != "null" {
+		return s
+	}
+	return normalize(v)
+}
+
+// readInput reads the named file, or stdin if the name is "-".
+func readInput(name string, stdin io.Reader) ([]byte, error) {
+	if name == "-" {
+		return ioutil.ReadAll(stdin)
+	}
+	return ioutil.ReadFile(name)
+}
+
+// readInputFormat reads the named file, or stdin if the name is "-", and
+// determines its format. If format is empty, it is detected from the file
+// name and content.
+func readInputFormat(name, format string, stdin io.Reader) ([]byte, Format, error) {
+	data, err := readInput(name, stdin)
+	if err != nil {
+		return nil, "", err
+	}
+
+	if format == "" {
+		return data, detectFormat(name, data), nil
+	}
+
+	f, err := parseFormat(format)
+	if err != nil {
+		return nil, "", err
+	}
+	return data, f, nil
+}
+
+// writeOutput writes the data to the named file, or stdout if the name is
+// "-".
+func writeOutput(name string, data []byte, stdout io.Writer) error {
+	if name == "-" {
+		_, err := stdout.Write(data)
+		return err
+	}
+	return writeFileAtomic(name, data)
+}
+
+// writeFileAtomic writes the data to a temporary file next to the named
+// file and renames it over the original, so readers never observe a
+// partially written file. The permissions of the original file are kept.
+func writeFileAtomic(name string, data []byte) (err error) {
+	mode := os.FileMode(0644)
+	if info, err := os.Stat(name); err == nil {
+		mode = info.Mode().Perm()
+	}
+
+	dir, base := filepath.Split(name)
+	if dir == "" {
+		dir = "."
+	}
+
+	f, err := ioutil.TempFile(dir, "."+base+".tmp")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		// clean up the temporary file if anything went wrong.
+		if err != nil {
+			f.Close()
+			os.Remove(f.Name())
+		}
+	}()
+
+	if _, err = f.Write(data); err != nil {
+		return err
+	}
+	if err = f.Chmod(mode); err != nil {
+		return err
+	}
+	if err = f.Sync(); err != nil {
+		return err
+	}
+	if err = f.Close(); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), name)
+}
+
+// loadEditable reads and decodes the named document for editing. If format
+// is empty, it is detected from the file name and content.
+func loadEditable(name, format string, stdin io.Reader) (*editableDocument, error) {
+	data, f, err := readInputFormat(name, format, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decodeEditable(data, f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s document: %s", f, err)
+	}
+	return doc, nil
+}
@@ -0,0 +1,255 @@
+package main
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type editTestFixture struct {
+	name     string
+	content  string
+	args     []string
+	status   int
+	expected string
+}
+
+func runEditTest(t *testing.T, fixture editTestFixture) {
+	t.Helper()
+
+	path := writeTestFile(t, fixture.name, fixture.content)
+	if err := os.Chmod(path, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// FILE is substituted with the path of the test file.
+	var args []string
+	for _, arg := range fixture.args {
+		if arg == "FILE" {
+			arg = path
+		}
+		args = append(args, arg)
+	}
+
+	runCLITest(t, cliTestFixture{
+		args:   args,
+		status: fixture.status,
+	})
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(fixture.expected, string(data)); diff != "" {
+		t.Errorf("%s was not edited as expected:\n%s", fixture.name, diff)
+	}
+
+	if info, err := os.Stat(path); err != nil {
+		t.Fatal(err)
+	} else if info.Mode().Perm() != 0600 {
+		t.Errorf("expected permissions of %s to be retained but got %s", fixture.name, info.Mode())
+	}
+}
+
+func TestRun_set(t *testing.T) {
+	// comments and key order are preserved in YAML documents.
+	runEditTest(t, editTestFixture{
+		name: "deployment.yaml",
+		content: `# web deployment
+spec:
+  # scaled by ops
+  replicas: 1
+  image: nginx # pinned
+`,
+		args: []string{"set", "FILE", ".spec.replicas", "3"},
+		expected: `# web deployment
+spec:
+  # scaled by ops
+  replicas: 3
+  image: nginx # pinned
+`,
+	})
+
+	// nodes reached through aliases and merge keys are copied rather than
+	// modified, so the anchored nodes are left untouched.
+	runEditTest(t, editTestFixture{
+		name: "config.yaml",
+		content: `base: &base
+  port: 80
+  tls:
+    enabled: false
+web: *base
+prod:
+  <<: *base
+  host: example.com
+`,
+		args: []string{"set", "FILE", ".prod.tls.enabled", "true"},
+		expected: `base: &base
+  port: 80
+  tls:
+    enabled: false
+web: *base
+prod:
+  <<: *base
+  host: example.com
+  tls:
+    enabled: true
+`,
+	})
+
+	runEditTest(t, editTestFixture{
+		name: "config.yaml",
+		content: `base: &base
+  port: 80
+web: *base
+prod:
+  <<: *base
+  port: 443
+`,
+		args: []string{"set", "FILE", ".web.port", "8080"},
+		expected: `base: &base
+  port: 80
+web:
+  port: 8080
+prod:
+  <<: *base
+  port: 443
+`,
+	})
+
+	runEditTest(t, editTestFixture{
+		name:    "config.json",
+		content: "{\n    \"debug\": false,\n    \"port\": 8080\n}\n",
+		args:    []string{"set", "FILE", ".name", "web"},
+		expected: `{
+    "debug": false,
+    "name": "web",
+    "port": 8080
+}
+`,
+	})
+
+	// minified documents stay minified.
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"port":8080,"debug":false}`,
+		args:     []string{"set", "FILE", ".port", "8081"},
+		expected: "{\"debug\":false,\"port\":8081}\n",
+	})
+
+	// values can be forced to be strings.
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"port": 8080}`,
+		args:     []string{"set", "-s", "FILE", ".port", "8081"},
+		expected: "{\"port\":\"8081\"}\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name:     "config.toml",
+		content:  "[server]\nport = 8080\n",
+		args:     []string{"set", "FILE", ".server.host", "localhost"},
+		expected: "[server]\nhost = \"localhost\"\nport = 8080\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"port": 8080}`,
+		args:     []string{"set", "FILE", ".port.number", "1"},
+		status:   exitError,
+		expected: `{"port": 8080}`,
+	})
+}
+
+func TestRun_delete(t *testing.T) {
+	runEditTest(t, editTestFixture{
+		name:     "config.yaml",
+		content:  "debug: true # remove me\nport: 8080 # keep me\n",
+		args:     []string{"delete", "FILE", ".debug"},
+		expected: "port: 8080 # keep me\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"hosts": ["a", "b", "c"]}`,
+		args:     []string{"delete", "FILE", ".hosts[1]"},
+		expected: "{\"hosts\":[\"a\",\"c\"]}\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name: "config.yaml",
+		content: `base: &base
+  tls:
+    enabled: false
+    cert: a.pem
+prod:
+  <<: *base
+`,
+		args: []string{"delete", "FILE", ".prod.tls.cert"},
+		expected: `base: &base
+  tls:
+    enabled: false
+    cert: a.pem
+prod:
+  <<: *base
+  tls:
+    enabled: false
+`,
+	})
+
+	// keys holding null are deleted.
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  "{\n  \"debug\": null,\n  \"port\": 8080\n}\n",
+		args:     []string{"delete", "FILE", ".debug"},
+		expected: "{\n  \"port\": 8080\n}\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name:     "config.yaml",
+		content:  "debug: null\nport: 8080\n",
+		args:     []string{"delete", "FILE", ".debug"},
+		expected: "port: 8080\n",
+	})
+
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"port": 8080}`,
+		args:     []string{"delete", "FILE", ".port[0]"},
+		status:   exitError,
+		expected: `{"port": 8080}`,
+	})
+
+	// the file is untouched when there is nothing to delete.
+	runEditTest(t, editTestFixture{
+		name:     "config.json",
+		content:  `{"port": 8080}`,
+		args:     []string{"delete", "FILE", ".debug"},
+		status:   exitNoMatch,
+		expected: `{"port": 8080}`,
+	})
+}
+
+func TestRun_get(t *testing.T) {
+	path := writeTestFile(t, "config.yaml", "server:\n  port: 8080\n")
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"get", path, ".server.port"},
+		stdout: "8080\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"get", "-o", "json", path, ".server"},
+		stdout: "{\n  \"port\": 8080\n}\n",
+	})
+}
+
+func TestRun_setStdin(t *testing.T) {
+	runCLITest(t, cliTestFixture{
+		args:   []string{"set", "-", ".port", "8081"},
+		stdin:  "port: 8080\n",
+		stdout: "port: 8081\n",
+	})
+}
@@ -0,0 +1,273 @@
+// Command selectr queries and edits JSON, YAML and TOML documents with
+// key-path notation.
+//
+// Usage:
+//
+//	selectr [flags] <selector> [file]
+//	selectr get [flags] <file> <selector>
+//	selectr set [flags] <file> <selector> <value>
+//	selectr delete [flags] <file> <selector>
+//	selectr repl [flags] <file>
+//
+// If no file is given, or the file is "-", the document is read from stdin
+// (and edited documents are written to stdout). The exit status is 0 if the
+// selector matched a value, 1 if it did not and 2 if an error occurred.
+package main
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/0xch4z/selectr"
+)
+
+// Exit statuses.
+const (
+	exitMatch   = 0
+	exitNoMatch = 1
+	exitError   = 2
+)
+
+const usage = `Usage:
+  selectr [flags] <selector> [file]
+  selectr get [flags] <file> <selector>
+  selectr set [flags] <file> <selector> <value>
+  selectr delete [flags] <file> <selector>
+  selectr repl [flags] <file>
+
+Query and edit JSON, YAML and TOML documents with key-path notation. If no
+file is given, or the file is "-", the document is read from stdin. Edited
+documents are written back atomically in their original format, or to
+stdout when read from stdin. YAML comments and key order are preserved;
+JSON and TOML keys are written in alphabetical order. The repl subcommand starts an interactive
+shell with tab completion for exploring a document.
+
+Exit status is 0 if the selector matched a value, 1 if it did not and 2 if
+an error occurred.
+
+Flags:
+`
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdin, os.Stdout, os.Stderr))
+}
+
+// command holds the flags shared by all subcommands.
+type command struct {
+	fs     *flag.FlagSet
+	input  string
+	output string
+	str    bool
+}
+
+// newCommand returns a command with its flags registered.
+func newCommand(name string, stderr io.Writer) *command {
+	c := &command{
+		fs: flag.NewFlagSet(name, flag.ContinueOnError),
+	}
+
+	c.fs.SetOutput(stderr)
+	c.fs.Usage = func() {
+		fmt.Fprint(stderr, usage)
+		c.fs.PrintDefaults()
+	}
+
+	c.fs.StringVar(&c.input, "input", "", "input format: json, yaml or toml (detected by default)")
+	c.fs.StringVar(&c.input, "i", "", "shorthand for -input")
+	c.fs.StringVar(&c.output, "output", string(OutputRaw), "output mode: raw, json or yaml")
+	c.fs.StringVar(&c.output, "o", string(OutputRaw), "shorthand for -output")
+	c.fs.BoolVar(&c.str, "string", false, "set: treat the value as a string rather than parsing it")
+	c.fs.BoolVar(&c.str, "s", false, "shorthand for -string")
+	return c
+}
+
+// parse parses the flags and asserts the number of positional arguments
+// is within [min, max]. ok is false if the command should exit with the
+// returned status.
+func (c *command) parse(args []string, min, max int) (status int, ok bool) {
+	if err := c.fs.Parse(args); err != nil {
+		if err == flag.ErrHelp {
+			return exitMatch, false
+		}
+		return exitError, false
+	}
+
+	if c.fs.NArg() < min || c.fs.NArg() > max {
+		c.fs.Usage()
+		return exitError, false
+	}
+	return 0, true
+}
+
+// run runs the command with the arguments and returns the exit status.
+func run(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	if len(args) != 0 {
+		switch args[0] {
+		case "get":
+			return runGet(args[1:], stdin, stdout, stderr)
+		case "set":
+			return runSet(args[1:], stdin, stdout, stderr)
+		case "delete":
+			return runDelete(args[1:], stdin, stdout, stderr)
+		case "repl":
+			return runREPL(args[1:], stdin, stdout, stderr)
+		}
+	}
+
+	c := newCommand("selectr", stderr)
+	if status, ok := c.parse(args, 1, 2); !ok {
+		return status
+	}
+	return query(c, c.fs.Arg(1), c.fs.Arg(0), stdin, stdout, stderr)
+}
+
+// runGet runs the get subcommand.
+func runGet(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("get", stderr)
+	if status, ok := c.parse(args, 2, 2); !ok {
+		return status
+	}
+	return query(c, c.fs.Arg(0), c.fs.Arg(1), stdin, stdout, stderr)
+}
+
+// query prints the value at the selector in the named document.
+func query(c *command, name, selector string, stdin io.Reader, stdout, stderr io.Writer) int {
+	mode, err := parseOutputMode(c.output)
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+
+	sel, err := selectr.Parse(selector)
+	if err != nil {
+		fmt.Fprintln(stderr, selectr.Diagnose(selector, err))
+		return exitError
+	}
+
+	doc, err := readDocument(name, c.input, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+
+	// missing keys and indices are reported as errors in strict mode, so
+	// a nil value is a null present in the document.
+	v, err := sel.Resolve(doc, selectr.Strict())
+	if err != nil {
+		fmt.Fprintln(stderr, selectr.Diagnose(selector, err))
+		return errorStatus(err)
+	}
+
+	out, err := render(v, mode)
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+	stdout.Write(out)
+	return exitMatch
+}
+
+// runSet runs the set subcommand.
+func runSet(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("set", stderr)
+	if status, ok := c.parse(args, 3, 3); !ok {
+		return status
+	}
+
+	return edit(c, stdin, stdout, stderr, func(sel *selectr.Selector, root interface{}) (interface{}, error) {
+		return sel.Set(root, parseValue(c.fs.Arg(2), c.str))
+	})
+}
+
+// runDelete runs the delete subcommand.
+func runDelete(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("delete", stderr)
+	if status, ok := c.parse(args, 2, 2); !ok {
+		return status
+	}
+
+	return edit(c, stdin, stdout, stderr, func(sel *selectr.Selector, root interface{}) (interface{}, error) {
+		// only report a match if there was a value to delete; a null
+		// present in the document is deleted.
+		if _, err := sel.Resolve(root, selectr.Strict()); err != nil {
+			if errorStatus(err) == exitNoMatch {
+				return nil, errNoMatch
+			}
+			return nil, err
+		}
+		return sel.Delete(root)
+	})
+}
+
+// errorStatus returns the exit status for an error that occurred while
+// resolving a selector: missing keys and indices did not match, any other
+// error (such as a TypeError) is an error.
+func errorStatus(err error) int {
+	if e, ok := err.(selectr.ResolveError); ok && (e.Code == "KeyError" || e.Code == "IndexError") {
+		return exitNoMatch
+	}
+	return exitError
+}
+
+// errNoMatch signals that the selector did not match a value.
+var errNoMatch = errors.New("no match")
+
+// edit loads the document named by the first argument, applies fn with the
+// selector given as the second argument and writes the document back.
+func edit(c *command, stdin io.Reader, stdout, stderr io.Writer, fn func(*selectr.Selector, interface{}) (interface{}, error)) int {
+	name, selector := c.fs.Arg(0), c.fs.Arg(1)
+
+	sel, err := selectr.Parse(selector)
+	if err != nil {
+		fmt.Fprintln(stderr, selectr.Diagnose(selector, err))
+		return exitError
+	}
+
+	doc, err := loadEditable(name, c.input, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+
+	if doc.root, err = fn(sel, doc.root); err != nil {
+		if err == errNoMatch {
+			return exitNoMatch
+		}
+		fmt.Fprintln(stderr, selectr.Diagnose(selector, err))
+		return exitError
+	}
+
+	out, err := doc.encode()
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+	if err := writeOutput(name, out, stdout); err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+	return exitMatch
+}
+
+// readDocument reads and decodes the document from the named file, or from
+// stdin if the name is empty or "-". If format is empty, it is detected
+// from the file name and content.
+func readDocument(name, format string, stdin io.Reader) (interface{}, error) {
+	if name == "" {
+		name = "-"
+	}
+
+	data, f, err := readInputFormat(name, format, stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := decode(data, f)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s document: %s", f, err)
+	}
+	return doc, nil
+}
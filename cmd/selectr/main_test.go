@@ -0,0 +1,186 @@
+package main
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type cliTestFixture struct {
+	args   []string
+	stdin  string
+	status int
+	stdout string
+	stderr string
+}
+
+func runCLITest(t *testing.T, fixture cliTestFixture) {
+	t.Helper()
+
+	var stdout, stderr bytes.Buffer
+	status := run(fixture.args, strings.NewReader(fixture.stdin), &stdout, &stderr)
+
+	if status != fixture.status {
+		t.Errorf("`selectr %s` exited with status %d; expected %d (stderr: %s)",
+			strings.Join(fixture.args, " "), status, fixture.status, stderr.String())
+	}
+	if diff := cmp.Diff(fixture.stdout, stdout.String()); diff != "" {
+		t.Errorf("stdout of `selectr %s` was not as expected:\n%s", strings.Join(fixture.args, " "), diff)
+	}
+	if diff := cmp.Diff(fixture.stderr, stderr.String()); fixture.stderr != "" && diff != "" {
+		t.Errorf("stderr of `selectr %s` was not as expected:\n%s", strings.Join(fixture.args, " "), diff)
+	}
+}
+
+// writeTestFile writes a file to a temporary directory and returns its path.
+func writeTestFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	dir, err := ioutil.TempDir("", "selectr")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRun_formats(t *testing.T) {
+	jsonFile := writeTestFile(t, "doc.json", `{"accounts": [{"id": 123, "name": "main"}]}`)
+	yamlFile := writeTestFile(t, "doc.yaml", "accounts:\n  - id: 123\n    name: main\n")
+	tomlFile := writeTestFile(t, "doc.toml", "[[accounts]]\nid = 123\nname = \"main\"\n")
+
+	for _, file := range []string{jsonFile, yamlFile, tomlFile} {
+		runCLITest(t, cliTestFixture{
+			args:   []string{".accounts[0].name", file},
+			stdout: "main\n",
+		})
+
+		runCLITest(t, cliTestFixture{
+			args:   []string{".accounts[0].id", file},
+			stdout: "123\n",
+		})
+	}
+
+	// the format can be specified explicitly.
+	runCLITest(t, cliTestFixture{
+		args:   []string{"-i", "yaml", ".name", "-"},
+		stdin:  "name: main",
+		stdout: "main\n",
+	})
+
+	// stdin is sniffed when no format is specified.
+	runCLITest(t, cliTestFixture{
+		args:   []string{".name"},
+		stdin:  `{"name": "main"}`,
+		stdout: "main\n",
+	})
+}
+
+func TestRun_output(t *testing.T) {
+	runCLITest(t, cliTestFixture{
+		args:   []string{".account"},
+		stdin:  `{"account": {"name": "main"}}`,
+		stdout: "{\n  \"name\": \"main\"\n}\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"-o", "json", ".account.name"},
+		stdin:  `{"account": {"name": "main"}}`,
+		stdout: "\"main\"\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"-output", "yaml", ".account"},
+		stdin:  `{"account": {"name": "main"}}`,
+		stdout: "name: main\n",
+	})
+
+	// numbers are printed without exponents.
+	runCLITest(t, cliTestFixture{
+		args:   []string{".n"},
+		stdin:  `{"n": 1000000}`,
+		stdout: "1000000\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{".n"},
+		stdin:  `{"n": 12345678901234567890}`,
+		stdout: "12345678901234567890\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"-i", "yaml", ".n", "-"},
+		stdin:  "n: 1.0e+6\n",
+		stdout: "1000000\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{"-o", "yaml", ".x"},
+		stdin:  `{"x": {"id": 12345678901234567890, "f": 1.5}}`,
+		stdout: "f: 1.5\nid: 12345678901234567890\n",
+	})
+}
+
+func TestRun_status(t *testing.T) {
+	runCLITest(t, cliTestFixture{
+		args:   []string{".missing"},
+		stdin:  `{"name": "main"}`,
+		status: exitNoMatch,
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{".names[1]"},
+		stdin:  `{"names": ["main"]}`,
+		status: exitNoMatch,
+	})
+
+	// a null present in the document is a match.
+	runCLITest(t, cliTestFixture{
+		args:   []string{".debug"},
+		stdin:  `{"debug": null}`,
+		stdout: "null\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{".name[0]"},
+		stdin:  `{"name": "main"}`,
+		status: exitError,
+		stderr: ".name[0]\n     ^\nTypeError: cannot resolve element '0' on type string\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{".name[0"},
+		stdin:  `{"name": "main"}`,
+		status: exitError,
+		stderr: ".name[0\n       ^\nexpected ]\n",
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{".name", "does-not-exist.json"},
+		status: exitError,
+	})
+
+	runCLITest(t, cliTestFixture{
+		args:   []string{},
+		status: exitError,
+	})
+}
+
+func TestRun_suggestions(t *testing.T) {
+	runCLITest(t, cliTestFixture{
+		args:   []string{".acounts[0]"},
+		stdin:  `{"accounts": []}`,
+		status: exitNoMatch,
+		stderr: ".acounts[0]\n^\nKeyError: key 'acounts' not found; did you mean 'accounts'?\n",
+	})
+}
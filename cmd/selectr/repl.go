@@ -0,0 +1,158 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/0xch4z/selectr"
+	"github.com/peterh/liner"
+)
+
+const replHelp = `Enter a selector to print the value it resolves to, e.g. .accounts[0].name.
+Press tab to complete keys and indices.
+
+Commands:
+  :output raw|json|yaml  set how values are printed (default json)
+  :help                  show this help
+  :quit                  exit the shell
+`
+
+// historyFile is the name of the file REPL history is persisted to in the
+// user's home directory.
+const historyFile = ".selectr_history"
+
+// lineReader reads lines of input from the user.
+type lineReader interface {
+	Prompt(prompt string) (string, error)
+	AppendHistory(item string)
+}
+
+// runREPL runs the repl subcommand.
+func runREPL(args []string, stdin io.Reader, stdout, stderr io.Writer) int {
+	c := newCommand("repl", stderr)
+	if status, ok := c.parse(args, 1, 1); !ok {
+		return status
+	}
+
+	doc, err := readDocument(c.fs.Arg(0), c.input, stdin)
+	if err != nil {
+		fmt.Fprintln(stderr, "selectr:", err)
+		return exitError
+	}
+
+	line := liner.NewLiner()
+	defer line.Close()
+
+	line.SetCtrlCAborts(true)
+	line.SetCompleter(func(partial string) []string {
+		return completeSelector(partial, doc)
+	})
+
+	if path, ok := historyPath(); ok {
+		if f, err := os.Open(path); err == nil {
+			line.ReadHistory(f)
+			f.Close()
+		}
+		defer func() {
+			if f, err := os.Create(path); err == nil {
+				line.WriteHistory(f)
+				f.Close()
+			}
+		}()
+	}
+
+	fmt.Fprintln(stdout, "Type :help for help.")
+	return repl(line, doc, stdout, stderr)
+}
+
+// historyPath returns the path of the history file.
+func historyPath() (string, bool) {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return "", false
+	}
+	return filepath.Join(home, historyFile), true
+}
+
+// repl reads selectors from the reader and prints the values they resolve
+// to until the input ends or the user quits.
+func repl(r lineReader, doc interface{}, stdout, stderr io.Writer) int {
+	mode := OutputJSON
+
+	for {
+		input, err := r.Prompt("> ")
+		if err != nil {
+			if err == io.EOF || errors.Is(err, liner.ErrPromptAborted) {
+				return exitMatch
+			}
+			fmt.Fprintln(stderr, "selectr:", err)
+			return exitError
+		}
+
+		input = strings.TrimSpace(input)
+		if input == "" {
+			continue
+		}
+		r.AppendHistory(input)
+
+		if strings.HasPrefix(input, ":") {
+			fields := strings.Fields(input)
+			switch fields[0] {
+			case ":quit", ":q", ":exit":
+				return exitMatch
+
+			case ":help", ":h":
+				fmt.Fprint(stdout, replHelp)
+
+			case ":output", ":o":
+				if len(fields) != 2 {
+					fmt.Fprintln(stderr, "usage: :output raw|json|yaml")
+					continue
+				}
+				m, err := parseOutputMode(fields[1])
+				if err != nil {
+					fmt.Fprintln(stderr, err)
+					continue
+				}
+				mode = m
+
+			default:
+				fmt.Fprintf(stderr, "unknown command '%s'; type :help for help\n", fields[0])
+			}
+			continue
+		}
+
+		sel, err := selectr.Parse(input)
+		if err != nil {
+			fmt.Fprintln(stderr, selectr.Diagnose(input, err))
+			continue
+		}
+
+		v, err := sel.Resolve(doc, selectr.Strict())
+		if err != nil {
+			fmt.Fprintln(stderr, selectr.Diagnose(input, err))
+			continue
+		}
+
+		out, err := render(v, mode)
+		if err != nil {
+			fmt.Fprintln(stderr, "selectr:", err)
+			continue
+		}
+		stdout.Write(out)
+	}
+}
+
+// completeSelector returns the selectors completing the partial selector
+// against the document.
+func completeSelector(partial string, doc interface{}) []string {
+	var completions []string
+	for _, s := range selectr.Complete(partial, doc) {
+		completions = append(completions, s.Selector)
+	}
+	return completions
+}
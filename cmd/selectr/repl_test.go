@@ -0,0 +1,84 @@
+package main
+
+import (
+	"bytes"
+	"io"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// scriptedReader is a lineReader that returns a fixed set of lines.
+type scriptedReader struct {
+	lines   []string
+	history []string
+}
+
+func (r *scriptedReader) Prompt(string) (string, error) {
+	if len(r.lines) == 0 {
+		return "", io.EOF
+	}
+	line := r.lines[0]
+	r.lines = r.lines[1:]
+	return line, nil
+}
+
+func (r *scriptedReader) AppendHistory(item string) {
+	r.history = append(r.history, item)
+}
+
+var replTestDocument = map[string]interface{}{
+	"accounts": []interface{}{
+		map[string]interface{}{"id": 123, "name": "main"},
+		map[string]interface{}{"id": 456, "name": "other"},
+	},
+	"active":       true,
+	"content-type": "application/json",
+}
+
+func TestREPL(t *testing.T) {
+	r := &scriptedReader{
+		lines: []string{
+			".accounts[0]",
+			"",
+			":output raw",
+			".accounts[1].name",
+			".accounts[",
+			":quit",
+			".active",
+		},
+	}
+
+	var stdout, stderr bytes.Buffer
+	if status := repl(r, replTestDocument, &stdout, &stderr); status != exitMatch {
+		t.Errorf("expected repl to exit with status %d but got %d", exitMatch, status)
+	}
+
+	if diff := cmp.Diff("{\n  \"id\": 123,\n  \"name\": \"main\"\n}\nother\n", stdout.String()); diff != "" {
+		t.Errorf("repl output was not as expected:\n%s", diff)
+	}
+	if diff := cmp.Diff(".accounts[\n          ^\nunexpected end of selector\n", stderr.String()); diff != "" {
+		t.Errorf("repl errors were not as expected:\n%s", diff)
+	}
+	if diff := cmp.Diff([]string{".accounts[0]", ":output raw", ".accounts[1].name", ".accounts[", ":quit"}, r.history); diff != "" {
+		t.Errorf("repl history was not as expected:\n%s", diff)
+	}
+}
+
+func TestCompleteSelector(t *testing.T) {
+	for partial, expected := range map[string][]string{
+		"":            {".accounts", ".active", "['content-type']"},
+		"acc":         {".accounts"},
+		".ac":         {".accounts", ".active"},
+		".accounts":   {".accounts"},
+		".accounts[":  {".accounts[0]", ".accounts[1]"},
+		".accounts[1": {".accounts[1]"},
+		".accounts.":  nil,
+		"['con":       {"['content-type']"},
+		".missing.":   nil,
+	} {
+		if diff := cmp.Diff(expected, completeSelector(partial, replTestDocument)); diff != "" {
+			t.Errorf("completions for `%s` were not as expected:\n%s", partial, diff)
+		}
+	}
+}
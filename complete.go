@@ -0,0 +1,213 @@
+package selectr
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/0xch4z/selectr/internal/parser"
+	"github.com/0xch4z/selectr/internal/parser/ast"
+)
+
+// Suggestion represents a completion of a partial selector.
+type Suggestion struct {
+	// Segment is the attribute or index expression selecting the key or
+	// index, e.g. `.name`, `['content-type']` or `[0]`.
+	Segment string
+
+	// Selector is the partial selector completed with the segment.
+	Selector string
+
+	// Key is the map key (string) or slice index (int) that the segment
+	// selects.
+	Key interface{}
+}
+
+// Complete returns the candidate keys and indices that can follow the
+// partial selector in the document.
+//
+// The partial selector is parsed as far as possible; a trailing `.`, an
+// unclosed `[` or an unterminated string are treated as the start of the
+// segment being completed. If the selector ends with an attribute
+// expression, it is treated as an incomplete key. The rest of the selector
+// is resolved against the document and the keys and indices of the value
+// it resolves to are suggested, sorted. Keys that are not valid identifiers
+// are quoted in an index expression.
+//
+// Example usage:
+//
+//	Complete(".accounts[0].na", doc)
+//	// => []Suggestion{{Segment: ".name", Selector: ".accounts[0].name", Key: "name"}}
+func Complete(partial string, doc interface{}) []Suggestion {
+	runes := []rune(partial)
+	exprs, err := parser.New(strings.NewReader(partial)).ParsePartial()
+
+	// cut is the position the segment being completed starts at.
+	cut := 0
+	if len(exprs) != 0 {
+		last := exprs[len(exprs)-1]
+		cut = last.EndPos()
+		if _, ok := last.(*ast.AttrExpr); ok && err == nil {
+			// an attribute expression at the end of a valid selector may
+			// still be being typed.
+			cut = last.StartPos()
+		}
+	}
+	if cut > len(runes) {
+		cut = len(runes)
+	}
+
+	prefix, fragment := string(runes[:cut]), strings.TrimSpace(string(runes[cut:]))
+	match, ok := fragmentMatcher(fragment, cut == 0)
+	if !ok {
+		return nil
+	}
+
+	sel, err := Parse(prefix)
+	if err != nil {
+		return nil
+	}
+	v, err := sel.Resolve(doc)
+	if err != nil {
+		return nil
+	}
+
+	// keep using index expressions for keys if the user started one.
+	bracketed := strings.HasPrefix(fragment, "[")
+
+	var suggestions []Suggestion
+	for _, e := range entries(v) {
+		if !match(e.key) {
+			continue
+		}
+
+		var segment string
+		if k, ok := e.key.(string); ok && bracketed {
+			segment = "[" + quoteKey(k) + "]"
+		} else {
+			segment = segmentFor(e.key)
+		}
+
+		suggestions = append(suggestions, Suggestion{
+			Segment:  segment,
+			Selector: strings.TrimRightFunc(prefix, isSpace) + segment,
+			Key:      e.key,
+		})
+	}
+	return suggestions
+}
+
+// fragmentMatcher returns a function that matches keys and indices which
+// complete the fragment of a segment. false is returned if the fragment
+// cannot be the start of a segment. If first is true, the fragment may be
+// an attribute expression with its dot omitted.
+func fragmentMatcher(fragment string, first bool) (func(key interface{}) bool, bool) {
+	switch {
+	case fragment == "":
+		return func(interface{}) bool { return true }, true
+
+	case strings.HasPrefix(fragment, "."):
+		name := fragment[1:]
+		if name != "" && !isIdent(name) {
+			return nil, false
+		}
+		return keyPrefixMatcher(name), true
+
+	case strings.HasPrefix(fragment, "["):
+		inner := strings.TrimSpace(fragment[1:])
+		if inner == "" {
+			return func(interface{}) bool { return true }, true
+		}
+
+		if inner[0] == '\'' || inner[0] == '"' {
+			// the string may or may not be terminated.
+			s := inner[1:]
+			if strings.HasSuffix(s, inner[:1]) {
+				s = s[:len(s)-1]
+			}
+			lit := &ast.StringLit{Node: &ast.Node{Lit: inner[:1] + s + inner[:1]}}
+			return keyPrefixMatcher(lit.Value().(string)), true
+		}
+
+		if strings.Trim(inner, "0123456789") == "" {
+			return func(key interface{}) bool {
+				i, ok := key.(int)
+				return ok && strings.HasPrefix(strconv.Itoa(i), inner)
+			}, true
+		}
+		return nil, false
+
+	case first && isIdent(fragment):
+		return keyPrefixMatcher(fragment), true
+	}
+	return nil, false
+}
+
+// keyPrefixMatcher returns a function that matches string keys beginning
+// with the prefix.
+func keyPrefixMatcher(prefix string) func(key interface{}) bool {
+	return func(key interface{}) bool {
+		k, ok := key.(string)
+		return ok && strings.HasPrefix(k, prefix)
+	}
+}
+
+// segmentFor returns the expression selecting the key: an index expression
+// for integer keys, an attribute expression for keys that are identifiers
+// and a quoted index expression otherwise.
+func segmentFor(key interface{}) string {
+	switch k := key.(type) {
+	case int:
+		return "[" + strconv.Itoa(k) + "]"
+	case string:
+		if isIdent(k) {
+			return "." + k
+		}
+		return "[" + quoteKey(k) + "]"
+	case wildcard:
+		return "[*]"
+	}
+	return ""
+}
+
+// isIdent determines if s is a valid identifier.
+func isIdent(s string) bool {
+	for i, ch := range s {
+		isLetter := (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z')
+		if i == 0 && !isLetter {
+			return false
+		} else if !isLetter && !(ch >= '0' && ch <= '9') && ch != '_' {
+			return false
+		}
+	}
+	return s != ""
+}
+
+// keyEscaper escapes characters that cannot appear as-is in a single
+// quoted string literal.
+var keyEscaper = strings.NewReplacer(
+	`\`, `\\`,
+	`'`, `\'`,
+	"\n", `\n`,
+	"\r", `\r`,
+	"\t", `\t`,
+	"\a", `\a`,
+	"\b", `\b`,
+	"\f", `\f`,
+	"\v", `\v`,
+	"\x1b", `\e`,
+	"\x00", `\0`,
+)
+
+// quoteKey returns the key as a single quoted string literal.
+func quoteKey(k string) string {
+	return "'" + keyEscaper.Replace(k) + "'"
+}
+
+// isSpace determines if a character is whitespace in a selector.
+func isSpace(ch rune) bool {
+	switch ch {
+	case '\t', '\n', '\v', '\f', '\r', ' ', '\u0085', '\u00A0':
+		return true
+	}
+	return false
+}
@@ -0,0 +1,123 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+var completeTestDocument = map[string]interface{}{
+	"accounts": []interface{}{
+		map[string]interface{}{"id": 123, "name": "main"},
+		map[string]interface{}{"id": 456, "name": "other"},
+	},
+	"active":       true,
+	"content-type": "application/json",
+	"it's":         "quoted",
+}
+
+type completeTestFixture struct {
+	partial  string
+	expected []string
+}
+
+func runCompleteTest(t *testing.T, fixture completeTestFixture) {
+	t.Helper()
+
+	var selectors []string
+	for _, s := range Complete(fixture.partial, completeTestDocument) {
+		selectors = append(selectors, s.Selector)
+	}
+
+	if diff := cmp.Diff(fixture.expected, selectors); diff != "" {
+		t.Errorf("completions for `%s` were not as expected:\n%s", fixture.partial, diff)
+	}
+}
+
+func TestComplete(t *testing.T) {
+	runCompleteTest(t, completeTestFixture{
+		partial:  "",
+		expected: []string{".accounts", ".active", "['content-type']", `['it\'s']`},
+	})
+
+	// the dot of the first attribute expression may be omitted.
+	runCompleteTest(t, completeTestFixture{
+		partial:  "acc",
+		expected: []string{".accounts"},
+	})
+
+	// a trailing attribute expression is completed as a prefix.
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".ac",
+		expected: []string{".accounts", ".active"},
+	})
+
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".accounts[1]",
+		expected: []string{".accounts[1].id", ".accounts[1].name"},
+	})
+
+	// trailing dot.
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".accounts[0].",
+		expected: []string{".accounts[0].id", ".accounts[0].name"},
+	})
+
+	// unclosed bracket.
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".accounts[",
+		expected: []string{".accounts[0]", ".accounts[1]"},
+	})
+
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".accounts[1",
+		expected: []string{".accounts[1]"},
+	})
+
+	// unterminated strings.
+	runCompleteTest(t, completeTestFixture{
+		partial:  "['con",
+		expected: []string{"['content-type']"},
+	})
+
+	runCompleteTest(t, completeTestFixture{
+		partial:  `["it\'`,
+		expected: []string{`['it\'s']`},
+	})
+
+	// no suggestions for values that cannot be resolved or have no keys.
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".missing.",
+		expected: nil,
+	})
+
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".active.",
+		expected: nil,
+	})
+
+	runCompleteTest(t, completeTestFixture{
+		partial:  ".accounts#",
+		expected: nil,
+	})
+}
+
+func TestComplete_roundTrip(t *testing.T) {
+	// every suggested selector should resolve to the value of its key.
+	for _, s := range Complete("", completeTestDocument) {
+		sel, err := Parse(s.Selector)
+		if err != nil {
+			t.Errorf("could not parse suggested selector `%s`: %s", s.Selector, err)
+			continue
+		}
+
+		v, err := sel.Resolve(completeTestDocument)
+		if err != nil {
+			t.Errorf("could not resolve suggested selector `%s`: %s", s.Selector, err)
+			continue
+		}
+		if diff := cmp.Diff(completeTestDocument[s.Key.(string)], v); diff != "" {
+			t.Errorf("suggested selector `%s` did not resolve to its key:\n%s", s.Selector, diff)
+		}
+	}
+}
@@ -0,0 +1,236 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	durationType   = reflect.TypeOf(time.Duration(0))
+	jsonNumberType = reflect.TypeOf(json.Number(""))
+	timeType       = reflect.TypeOf(time.Time{})
+	yamlNodeType   = reflect.TypeOf(&yaml.Node{})
+)
+
+// conversionError signals that a resolved value could not be converted to
+// the requested type.
+type conversionError struct {
+	// code is "TypeError" if the type of the value cannot be converted and
+	// "ValueError" if the value itself cannot be.
+	code string
+	msg  string
+}
+
+func (err conversionError) Error() string {
+	return err.msg
+}
+
+// errCannotConvert returns an error signaling that values of the type of v
+// cannot be converted to t.
+func errCannotConvert(v interface{}, t reflect.Type) conversionError {
+	return conversionError{
+		code: "TypeError",
+		msg:  fmt.Sprintf("cannot convert %T to %s", v, t),
+	}
+}
+
+// errInvalidValue returns an error signaling that v cannot be converted to
+// t for the reason.
+func errInvalidValue(v interface{}, t reflect.Type, reason string) conversionError {
+	return conversionError{
+		code: "ValueError",
+		msg:  fmt.Sprintf("cannot convert %v to %s: %s", v, t, reason),
+	}
+}
+
+// convert converts a resolved value to the type t. Values assignable to t
+// are returned as-is; otherwise:
+//
+//   - yaml.v3 nodes are decoded before they are converted, unless t is
+//     *yaml.Node.
+//   - nil converts to the zero value of interfaces, pointers, slices and
+//     maps only.
+//   - integers, floats and json.Numbers convert to any integer type if they
+//     hold an integer within its range, and to any float type.
+//   - strings convert to time.Duration as parsed by time.ParseDuration, and
+//     to time.Time as parsed in the RFC 3339 format.
+//   - strings (but not json.Numbers) and booleans convert to types of the
+//     same kind.
+//   - slices and arrays convert to slices element-wise.
+func convert(v interface{}, t reflect.Type) (reflect.Value, error) {
+	if n, ok := v.(*yaml.Node); ok && t != yamlNodeType {
+		var decoded interface{}
+		if err := derefYAMLNode(n).Decode(&decoded); err != nil {
+			return reflect.Value{}, conversionError{code: "ValueError", msg: err.Error()}
+		}
+		v = decoded
+	}
+
+	if v == nil {
+		switch t.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Slice, reflect.Map:
+			return reflect.Zero(t), nil
+		}
+		return reflect.Value{}, conversionError{
+			code: "TypeError",
+			msg:  fmt.Sprintf("cannot convert nil to %s", t),
+		}
+	}
+
+	rv := reflect.ValueOf(v)
+	if rv.Type().AssignableTo(t) {
+		out := reflect.New(t).Elem()
+		out.Set(rv)
+		return out, nil
+	}
+
+	switch t {
+	case durationType:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, errCannotConvert(v, t)
+		}
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return reflect.Value{}, errInvalidValue(fmt.Sprintf("'%s'", s), t, "invalid duration")
+		}
+		return reflect.ValueOf(d), nil
+
+	case timeType:
+		s, ok := v.(string)
+		if !ok {
+			return reflect.Value{}, errCannotConvert(v, t)
+		}
+		tm, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return reflect.Value{}, errInvalidValue(fmt.Sprintf("'%s'", s), t, "not an RFC 3339 time")
+		}
+		return reflect.ValueOf(tm), nil
+	}
+
+	out := reflect.New(t).Elem()
+
+	switch t.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := toInt64(v, rv, t)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if out.OverflowInt(i) {
+			return reflect.Value{}, errInvalidValue(v, t, "out of range")
+		}
+		out.SetInt(i)
+		return out, nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if k := rv.Kind(); k >= reflect.Uint && k <= reflect.Uintptr {
+			if out.OverflowUint(rv.Uint()) {
+				return reflect.Value{}, errInvalidValue(v, t, "out of range")
+			}
+			out.SetUint(rv.Uint())
+			return out, nil
+		}
+		i, err := toInt64(v, rv, t)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+		if i < 0 || out.OverflowUint(uint64(i)) {
+			return reflect.Value{}, errInvalidValue(v, t, "out of range")
+		}
+		out.SetUint(uint64(i))
+		return out, nil
+
+	case reflect.Float32, reflect.Float64:
+		f, ok := toFloat64(v, rv)
+		if !ok {
+			return reflect.Value{}, errCannotConvert(v, t)
+		}
+		if out.OverflowFloat(f) {
+			return reflect.Value{}, errInvalidValue(v, t, "out of range")
+		}
+		out.SetFloat(f)
+		return out, nil
+
+	case reflect.String, reflect.Bool:
+		if rv.Kind() == t.Kind() && rv.Type() != jsonNumberType {
+			return rv.Convert(t), nil
+		}
+
+	case reflect.Slice:
+		src := indirect(rv)
+		if src.Kind() != reflect.Slice && src.Kind() != reflect.Array {
+			break
+		}
+		out = reflect.MakeSlice(t, src.Len(), src.Len())
+		for i := 0; i < src.Len(); i++ {
+			elem, err := convert(src.Index(i).Interface(), t.Elem())
+			if err != nil {
+				convErr := err.(conversionError)
+				convErr.msg = fmt.Sprintf("element %d: %s", i, convErr.msg)
+				return reflect.Value{}, convErr
+			}
+			out.Index(i).Set(elem)
+		}
+		return out, nil
+	}
+
+	return reflect.Value{}, errCannotConvert(v, t)
+}
+
+// toInt64 converts an integer, a float holding an integer or a json.Number
+// holding an integer to an int64.
+func toInt64(v interface{}, rv reflect.Value, t reflect.Type) (int64, error) {
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return rv.Int(), nil
+
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		if rv.Uint() > math.MaxInt64 {
+			return 0, errInvalidValue(v, t, "out of range")
+		}
+		return int64(rv.Uint()), nil
+	}
+
+	f, ok := toFloat64(v, rv)
+	if !ok {
+		return 0, errCannotConvert(v, t)
+	}
+	if n, ok := v.(json.Number); ok {
+		// json.Numbers can hold integers too large to be floats exactly.
+		if i, err := n.Int64(); err == nil {
+			return i, nil
+		}
+	}
+
+	switch {
+	case f != math.Trunc(f):
+		return 0, errInvalidValue(v, t, "not an integer")
+	case f < math.MinInt64 || f >= math.MaxInt64:
+		return 0, errInvalidValue(v, t, "out of range")
+	}
+	return int64(f), nil
+}
+
+// toFloat64 converts a number or a json.Number to a float64. false is
+// returned if v is not a number.
+func toFloat64(v interface{}, rv reflect.Value) (float64, bool) {
+	if n, ok := v.(json.Number); ok {
+		f, err := n.Float64()
+		return f, err == nil
+	}
+
+	switch rv.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return float64(rv.Int()), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		return float64(rv.Uint()), true
+	case reflect.Float32, reflect.Float64:
+		return rv.Float(), true
+	}
+	return 0, false
+}
@@ -0,0 +1,114 @@
+package selectr
+
+import "gopkg.in/yaml.v3"
+
+// deepCopy returns a copy of the value that shares no maps, slices or
+// yaml.v3 nodes with it. Other values are returned as-is.
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = deepCopy(val)
+		}
+		return m
+
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		s := make([]interface{}, len(v))
+		for i, val := range v {
+			s[i] = deepCopy(val)
+		}
+		return s
+
+	case *yaml.Node:
+		return copyYAMLNode(v, map[*yaml.Node]*yaml.Node{})
+	}
+	return v
+}
+
+// copyYAMLNode returns a deep copy of the node. Aliases in the copy refer
+// to the copies of their anchored nodes, which are tracked in copies.
+func copyYAMLNode(n *yaml.Node, copies map[*yaml.Node]*yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if c, ok := copies[n]; ok {
+		return c
+	}
+
+	c := &yaml.Node{}
+	*c = *n
+	copies[n] = c
+
+	if n.Content != nil {
+		c.Content = make([]*yaml.Node, len(n.Content))
+		for i, child := range n.Content {
+			c.Content[i] = copyYAMLNode(child, copies)
+		}
+	}
+	c.Alias = copyYAMLNode(n.Alias, copies)
+	return c
+}
+
+// shallowCopy returns a copy of the map, slice or yaml.v3 node that shares
+// its entries with it. Other values are returned as-is.
+func shallowCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for k, val := range v {
+			m[k] = val
+		}
+		return m
+
+	case map[interface{}]interface{}:
+		m := make(map[interface{}]interface{}, len(v))
+		for k, val := range v {
+			m[k] = val
+		}
+		return m
+
+	case []interface{}:
+		if v == nil {
+			return v
+		}
+		return append([]interface{}(nil), v...)
+
+	case *yaml.Node:
+		return shallowCopyYAMLNode(v)
+	}
+	return v
+}
+
+// shallowCopyYAMLNode returns a copy of the node that shares its children
+// with it. The content of document nodes is copied too, and aliases are
+// replaced by a copy of the node they refer to, as both are modified in
+// place of the node.
+func shallowCopyYAMLNode(n *yaml.Node) *yaml.Node {
+	if n == nil {
+		return nil
+	}
+	if n.Kind == yaml.AliasNode {
+		return shallowCopyYAMLNode(n.Alias)
+	}
+
+	c := &yaml.Node{}
+	*c = *n
+	if n.Content != nil {
+		c.Content = append([]*yaml.Node(nil), n.Content...)
+	}
+	if n.Kind == yaml.DocumentNode && len(c.Content) != 0 {
+		c.Content[0] = shallowCopyYAMLNode(c.Content[0])
+	}
+	return c
+}
@@ -0,0 +1,336 @@
+package selectr
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// tagName is the name of the struct tag holding the selector of a field.
+const tagName = "selectr"
+
+// FieldError represents an error decoding a field of a struct.
+type FieldError struct {
+	// Field is the path of the field from the decoded struct, e.g.
+	// `Spec.Containers[1].Image`.
+	Field string
+
+	// Selector is the selector of the field, relative to the value the
+	// struct holding the field was decoded from.
+	Selector string
+
+	Err error
+}
+
+// Error implements (error).Error
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("%s: %s", e.Field, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError represents the errors decoding the fields of a struct.
+type DecodeError []*FieldError
+
+// DecodeError implements (error).Error
+func (l DecodeError) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Push adds a new field error to the collection.
+func (l *DecodeError) Push(err *FieldError) {
+	*l = append(*l, err)
+}
+
+// fieldTag represents the parsed `selectr` tag of a field.
+type fieldTag struct {
+	selector   string
+	required   bool
+	hasDefault bool
+	def        string
+}
+
+// parseFieldTag parses a tag of the form `<selector>[,required][,default=<value>]`.
+// The default value extends to the end of the tag, so it may contain commas.
+func parseFieldTag(tag string) (fieldTag, error) {
+	parts := strings.Split(tag, ",")
+	ft := fieldTag{selector: parts[0]}
+
+	for i, opt := range parts[1:] {
+		switch {
+		case opt == "required":
+			ft.required = true
+		case strings.HasPrefix(opt, "default="):
+			ft.hasDefault = true
+			ft.def = strings.Join(append([]string{strings.TrimPrefix(opt, "default=")}, parts[i+2:]...), ",")
+			return ft, nil
+		default:
+			return ft, fmt.Errorf("unknown tag option '%s'", opt)
+		}
+	}
+	return ft, nil
+}
+
+// Decode fills the struct pointed to by dst with values resolved from the
+// document. Each field tagged with a selector is filled with the value it
+// resolves to, converted to the type of the field as by Get:
+//
+//	type Deployment struct {
+//		Name     string        `selectr:".metadata.name,required"`
+//		Image    string        `selectr:".spec.containers[0].image"`
+//		Replicas int           `selectr:".spec.replicas,default=1"`
+//		Timeout  time.Duration `selectr:".spec.timeout,default=30s"`
+//	}
+//
+// Fields holding structs or pointers to structs are decoded from the value
+// their selector resolves to, and fields holding slices of structs from
+// each element of the sequence it resolves to, with the selectors of their
+// fields relative to that value. Untagged struct fields are decoded from
+// the same value as the struct holding them; other untagged fields and
+// fields tagged `selectr:"-"` are left untouched.
+//
+// Missing keys and indices leave fields untouched, or set them to their
+// default if one is given. The default is converted from its text, or from
+// the scalar it is as YAML if the text cannot be converted directly. Fields
+// marked required must resolve to a value that is not null.
+//
+// All fields are decoded before returning; the errors of the fields that
+// could not be decoded are returned as a DecodeError.
+//
+// If the document is a *Tracker, the document it wraps is decoded and the
+// paths read are recorded, as by (*Tracker).Decode.
+func Decode(doc interface{}, dst interface{}, opts ...ResolveOption) error {
+	if t, ok := doc.(*Tracker); ok {
+		return t.Decode(dst, opts...)
+	}
+	return decode(doc, dst, opts, nil)
+}
+
+// decode decodes the document into the struct pointed to by dst, calling
+// record, if not nil, with the path of every value decoded into a field.
+func decode(doc interface{}, dst interface{}, opts []ResolveOption, record func([]interface{})) error {
+	rv := reflect.ValueOf(dst)
+	if rv.Kind() != reflect.Ptr || rv.IsNil() || rv.Elem().Kind() != reflect.Struct {
+		return fmt.Errorf("cannot decode into %T; expected a non-nil pointer to a struct", dst)
+	}
+
+	// missing keys are detected in strict mode and reported for required
+	// fields only.
+	d := &decoder{
+		opts:   append(append([]ResolveOption{}, opts...), Strict()),
+		record: record,
+	}
+	d.decodeStruct(doc, nil, rv.Elem(), "")
+	if len(d.errs) != 0 {
+		return d.errs
+	}
+	return nil
+}
+
+// decoder decodes documents into structs, collecting the errors of the
+// fields.
+type decoder struct {
+	opts []ResolveOption
+	errs DecodeError
+
+	// record is called with the path of every value decoded into a field
+	// as a whole, i.e. not field by field. It may be nil.
+	record func(keys []interface{})
+}
+
+// decodeStruct decodes the fields of the struct from the value at the path
+// of keys.
+func (d *decoder) decodeStruct(v interface{}, keys []interface{}, dst reflect.Value, path string) {
+	t := dst.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// the field is unexported.
+			continue
+		}
+
+		field := f.Name
+		if path != "" {
+			field = path + "." + f.Name
+		}
+
+		tag, ok := f.Tag.Lookup(tagName)
+		if !ok {
+			// the fields of embedded unexported structs can be set, but
+			// pointers to them cannot be allocated.
+			if isDecodableStruct(f.Type) && (dst.Field(i).CanSet() || f.Type.Kind() == reflect.Struct) {
+				d.decodeValue(nil, v, keys, dst.Field(i), field)
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		ft, err := parseFieldTag(tag)
+		if err != nil {
+			d.errs.Push(&FieldError{Field: field, Selector: tag, Err: err})
+			continue
+		}
+		d.decodeField(v, keys, dst.Field(i), field, ft)
+	}
+}
+
+// decodeField decodes the field from the value its tag's selector resolves
+// to on the value at the path of keys.
+func (d *decoder) decodeField(v interface{}, keys []interface{}, dst reflect.Value, field string, tag fieldTag) {
+	fail := func(err error) {
+		d.errs.Push(&FieldError{Field: field, Selector: tag.selector, Err: err})
+	}
+
+	sel, err := Parse(tag.selector)
+	if err != nil {
+		fail(err)
+		return
+	}
+
+	fv, err := sel.Resolve(v, d.opts...)
+	if err != nil {
+		var resolveErr ResolveError
+		isMissing := errors.As(err, &resolveErr) && (resolveErr.Code == "KeyError" || resolveErr.Code == "IndexError")
+		if !isMissing || tag.required {
+			fail(err)
+			return
+		}
+		fv = nil
+	}
+	if n, ok := fv.(*yaml.Node); ok {
+		if n = derefYAMLNode(n); n == nil || n.ShortTag() == "!!null" {
+			fv = nil
+		}
+	}
+
+	fieldKeys := append(keys[:len(keys):len(keys)], sel.keys()...)
+	if fv == nil {
+		if err == nil && d.record != nil {
+			// a null in the document is read, even though it leaves the
+			// field untouched.
+			d.record(fieldKeys)
+		}
+
+		switch {
+		case tag.required:
+			fail(ResolveError{
+				Code: "ValueError",
+				Msg:  "required value is null",
+				Pos:  sel.lastPos(),
+				Path: sel.String(),
+			})
+		case tag.hasDefault:
+			def, err := convertDefault(tag.def, dst.Type())
+			if err != nil {
+				fail(fmt.Errorf("invalid default: %s", err))
+				return
+			}
+			dst.Set(def)
+		}
+		return
+	}
+
+	if err := d.decodeValue(sel, fv, fieldKeys, dst, field); err != nil {
+		fail(err)
+	}
+}
+
+// decodeValue decodes the value resolved by the selector at the path of
+// keys into dst. The selector is nil for values decoded into untagged
+// structs. The errors of nested fields are collected; an error is returned
+// if the value itself cannot be converted.
+func (d *decoder) decodeValue(sel *Selector, v interface{}, keys []interface{}, dst reflect.Value, field string) error {
+	t := dst.Type()
+
+	switch {
+	case v != nil && reflect.TypeOf(v).AssignableTo(t):
+		dst.Set(reflect.ValueOf(v))
+
+	case t.Kind() == reflect.Ptr && isDecodableStruct(t):
+		if dst.IsNil() {
+			dst.Set(reflect.New(t.Elem()))
+		}
+		d.decodeStruct(v, keys, dst.Elem(), field)
+		return nil
+
+	case isDecodableStruct(t):
+		d.decodeStruct(v, keys, dst, field)
+		return nil
+
+	case t.Kind() == reflect.Slice && isDecodableStruct(t.Elem()) && isSequence(v):
+		es := entries(v)
+		out := reflect.MakeSlice(t, len(es), len(es))
+		for i, e := range es {
+			// elements are structs, which are decoded field by field.
+			d.decodeValue(sel, e.val, appendKey(keys, e.key), out.Index(i), fmt.Sprintf("%s[%d]", field, i))
+		}
+		dst.Set(out)
+		return nil
+
+	default:
+		rv, err := sel.convert(v, t)
+		if err != nil {
+			return err
+		}
+		dst.Set(rv)
+	}
+
+	if d.record != nil {
+		d.record(keys)
+	}
+	return nil
+}
+
+// isDecodableStruct determines if values of the type are decoded field by
+// field: structs and pointers to structs other than time.Time.
+func isDecodableStruct(t reflect.Type) bool {
+	if t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	return t.Kind() == reflect.Struct && t != timeType
+}
+
+// isSequence determines if the value is a slice, an array or a yaml.v3
+// sequence node.
+func isSequence(v interface{}) bool {
+	if n, ok := v.(*yaml.Node); ok {
+		n = derefYAMLNode(n)
+		return n != nil && n.Kind == yaml.SequenceNode
+	}
+	rv := indirect(reflect.ValueOf(v))
+	return rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array
+}
+
+// convertDefault converts the default value of a field to its type. The
+// text is converted as-is if possible, e.g. for strings and durations, and
+// as the YAML scalar it holds otherwise, e.g. for numbers and booleans.
+func convertDefault(def string, t reflect.Type) (reflect.Value, error) {
+	rv, err := convert(def, t)
+	if err == nil {
+		return rv, nil
+	}
+
+	var v interface{}
+	if yaml.Unmarshal([]byte(def), &v) != nil {
+		return reflect.Value{}, err
+	}
+	if rv, yamlErr := convert(v, t); yamlErr == nil {
+		return rv, nil
+	}
+	return reflect.Value{}, err
+}
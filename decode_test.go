@@ -0,0 +1,179 @@
+package selectr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type decodeTestContainer struct {
+	Name  string   `selectr:".name,required"`
+	Image string   `selectr:".image"`
+	Ports []int    `selectr:".ports"`
+	Args  []string `selectr:".args,default=[--verbose]"`
+}
+
+type decodeTestMetadata struct {
+	Name      string `selectr:".metadata.name,required"`
+	Namespace string `selectr:".metadata.namespace,default=default"`
+}
+
+type decodeTestDeployment struct {
+	decodeTestMetadata
+
+	Replicas   int                   `selectr:".spec.replicas,default=1"`
+	Paused     bool                  `selectr:".spec.paused,default=false"`
+	Timeout    time.Duration         `selectr:".spec.progressDeadline,default=10m"`
+	Created    time.Time             `selectr:".metadata.creationTimestamp"`
+	Image      string                `selectr:".spec.template.spec.containers[0].image"`
+	Containers []decodeTestContainer `selectr:".spec.template.spec.containers"`
+	Strategy   *struct {
+		Type string `selectr:".type"`
+	} `selectr:".spec.strategy"`
+	Labels   map[string]interface{} `selectr:".metadata.labels"`
+	Ignored  string                 `selectr:"-"`
+	Untagged string
+}
+
+const decodeTestDocument = `{
+	"metadata": {
+		"name": "web",
+		"creationTimestamp": "2020-05-01T12:00:00Z",
+		"labels": {"app": "web"}
+	},
+	"spec": {
+		"replicas": 3,
+		"strategy": {"type": "RollingUpdate"},
+		"template": {"spec": {"containers": [
+			{"name": "app", "image": "web:1.0", "ports": [80, 443]},
+			{"name": "sidecar", "image": "proxy:2.1", "args": ["--port", "8080"]}
+		]}}
+	}
+}`
+
+func TestDecode(t *testing.T) {
+	expected := decodeTestDeployment{
+		decodeTestMetadata: decodeTestMetadata{Name: "web", Namespace: "default"},
+		Replicas:           3,
+		Timeout:            10 * time.Minute,
+		Created:            time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC),
+		Image:              "web:1.0",
+		Containers: []decodeTestContainer{
+			{Name: "app", Image: "web:1.0", Ports: []int{80, 443}, Args: []string{"--verbose"}},
+			{Name: "sidecar", Image: "proxy:2.1", Args: []string{"--port", "8080"}},
+		},
+		Strategy: &struct {
+			Type string `selectr:".type"`
+		}{Type: "RollingUpdate"},
+		Labels:   map[string]interface{}{"app": "web"},
+		Ignored:  "kept",
+		Untagged: "kept",
+	}
+
+	var jsonDoc map[string]interface{}
+	json.Unmarshal([]byte(decodeTestDocument), &jsonDoc)
+
+	var yamlDoc yaml.Node
+	yaml.Unmarshal([]byte(decodeTestDocument), &yamlDoc)
+
+	for _, doc := range []interface{}{jsonDoc, &yamlDoc} {
+		result := decodeTestDeployment{Ignored: "kept", Untagged: "kept"}
+		if err := Decode(doc, &result); err != nil {
+			t.Errorf("could not decode %T: %s", doc, err)
+			continue
+		}
+		if diff := cmp.Diff(expected, result, cmp.AllowUnexported(decodeTestDeployment{})); diff != "" {
+			t.Errorf("%T was not decoded as expected:\n%s", doc, diff)
+		}
+	}
+}
+
+func TestDecode_error(t *testing.T) {
+	var doc map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"metadata": {"nmae": "web"},
+		"spec": {
+			"replicas": 1.5,
+			"template": {"spec": {"containers": [{"image": "web:1.0"}, {"name": null}]}}
+		}
+	}`), &doc)
+
+	var result decodeTestDeployment
+	err := Decode(doc, &result)
+
+	expected := DecodeError{
+		{
+			Field:    "decodeTestMetadata.Name",
+			Selector: ".metadata.name",
+			Err: ResolveError{
+				Code:        "KeyError",
+				Msg:         "key 'name' not found in '.metadata'; did you mean 'nmae'?",
+				Pos:         9,
+				Path:        ".metadata",
+				Suggestions: []string{"nmae"},
+			},
+		},
+		{
+			Field:    "Replicas",
+			Selector: ".spec.replicas",
+			Err:      ResolveError{Code: "ValueError", Msg: "cannot convert 1.5 to int: not an integer", Pos: 5, Path: ".spec.replicas"},
+		},
+		{
+			Field:    "Containers[0].Name",
+			Selector: ".name",
+			Err: ResolveError{
+				Code: "KeyError",
+				Msg:  "key 'name' not found",
+				Pos:  0,
+			},
+		},
+		{
+			Field:    "Containers[1].Name",
+			Selector: ".name",
+			Err:      ResolveError{Code: "ValueError", Msg: "required value is null", Pos: 0, Path: ".name"},
+		},
+	}
+	if diff := cmp.Diff(expected, err); diff != "" {
+		t.Errorf("errors for decoding were not as expected:\n%s", diff)
+	}
+
+	// fields without errors are still decoded.
+	if result.Image != "web:1.0" {
+		t.Errorf("expected Image to be decoded as 'web:1.0' but got '%s'", result.Image)
+	}
+
+	var resolveErr ResolveError
+	if !errors.As(err.(DecodeError)[1], &resolveErr) || resolveErr.Code != "ValueError" {
+		t.Errorf("expected field errors to unwrap to a ResolveError but got %v", err.(DecodeError)[1].Err)
+	}
+}
+
+func TestDecode_invalid(t *testing.T) {
+	var s struct {
+		Field string `selectr:".a,requried"`
+		Count int    `selectr:".b,default=many"`
+		Bad   string `selectr:".c["`
+	}
+
+	err, ok := Decode(map[string]interface{}{}, &s).(DecodeError)
+	if !ok || len(err) != 3 {
+		t.Fatalf("expected 3 field errors but got %v", err)
+	}
+	for i, msg := range []string{
+		"Field: unknown tag option 'requried'",
+		"Count: invalid default: cannot convert string to int",
+		"Bad: unexpected end of selector",
+	} {
+		if err[i].Error() != msg {
+			t.Errorf("expected error '%s' but got '%s'", msg, err[i])
+		}
+	}
+
+	if err := Decode(map[string]interface{}{}, s); err == nil {
+		t.Error("expected an error decoding into a non-pointer")
+	}
+}
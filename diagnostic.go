@@ -0,0 +1,76 @@
+package selectr
+
+import (
+	"errors"
+	"strings"
+
+	"github.com/0xch4z/selectr/internal/parser"
+)
+
+// ErrorPos returns the position in the key-path that a parse or resolve
+// error occurred at. false is returned if the error does not carry a
+// position.
+func ErrorPos(err error) (int, bool) {
+	var (
+		resolveErr ResolveError
+		parseErr   *parser.Error
+		parseErrs  parser.ErrorList
+	)
+
+	switch {
+	case errors.As(err, &resolveErr):
+		return resolveErr.Pos, true
+	case errors.As(err, &parseErr):
+		return parseErr.Pos, true
+	case errors.As(err, &parseErrs) && len(parseErrs) != 0:
+		return parseErrs[0].Pos, true
+	}
+	return 0, false
+}
+
+// Diagnose formats the error with the offending line of the key-path and a
+// caret pointing at the position the error occurred at:
+//
+//	.accounts[0.name
+//	           ^
+//	expected ]
+//
+// If the error does not carry a position, only the error message is
+// returned.
+func Diagnose(selector string, err error) string {
+	pos, ok := ErrorPos(err)
+	if !ok {
+		return err.Error()
+	}
+
+	// positions are counted in runes.
+	runes := []rune(selector)
+	if pos > len(runes) {
+		pos = len(runes)
+	}
+
+	// only print the line the error occurred on, as whitespace (including
+	// newlines) may separate expressions.
+	start, end := pos, pos
+	for start > 0 && runes[start-1] != '\n' {
+		start--
+	}
+	for end < len(runes) && runes[end] != '\n' {
+		end++
+	}
+
+	var b strings.Builder
+	b.WriteString(string(runes[start:end]))
+	b.WriteByte('\n')
+	for _, ch := range runes[start:pos] {
+		// preserve tabs so the caret lines up with the selector.
+		if ch == '\t' {
+			b.WriteRune(ch)
+		} else {
+			b.WriteByte(' ')
+		}
+	}
+	b.WriteString("^\n")
+	b.WriteString(err.Error())
+	return b.String()
+}
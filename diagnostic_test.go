@@ -0,0 +1,30 @@
+package selectr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestDiagnose(t *testing.T) {
+	_, err := Parse(".accounts[0.name")
+	expected := ".accounts[0.name\n           ^\nexpected ]"
+	if diff := cmp.Diff(expected, Diagnose(".accounts[0.name", err)); diff != "" {
+		t.Errorf("parse error diagnostic was not as expected:\n%s", diff)
+	}
+
+	sel, _ := Parse(".foo\n\t.bar[1]")
+	_, err = sel.Resolve(map[string]interface{}{
+		"foo": map[string]interface{}{"bar": "baz"},
+	})
+	expected = "\t.bar[1]\n\t    ^\nTypeError: cannot resolve element '1' on type string"
+	if diff := cmp.Diff(expected, Diagnose(".foo\n\t.bar[1]", err)); diff != "" {
+		t.Errorf("resolve error diagnostic was not as expected:\n%s", diff)
+	}
+
+	// errors without a position are returned as-is.
+	if diag := Diagnose(".foo", errors.New("oops")); diag != "oops" {
+		t.Errorf("expected diagnostic for error without position to be 'oops' but got '%s'", diag)
+	}
+}
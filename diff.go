@@ -0,0 +1,348 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ChangeOp is the kind of a change between two documents.
+type ChangeOp string
+
+const (
+	// Added signals a key or element that only exists in the new document.
+	Added ChangeOp = "added"
+
+	// Removed signals a key or element that only exists in the old
+	// document.
+	Removed ChangeOp = "removed"
+
+	// Modified signals a value that differs between the documents.
+	Modified ChangeOp = "modified"
+)
+
+// Change represents a difference between two documents.
+type Change struct {
+	Op ChangeOp
+
+	// Path is the canonical selector of the value that changed.
+	Path *Selector
+
+	// Old is the value in the old document; it is nil for added values.
+	Old interface{}
+
+	// New is the value in the new document; it is nil for removed values.
+	New interface{}
+
+	// patchPath is the path of the change once the changes before it are
+	// applied, if it differs from Path.
+	patchPath *Selector
+}
+
+// String returns the change as a line of text, e.g. `~ .replicas: 2 => 3`,
+// `+ .labels.app: "web"` or `- .ports[1]: 443`. Values are written as JSON.
+func (c Change) String() string {
+	switch c.Op {
+	case Added:
+		return fmt.Sprintf("+ %s: %s", c.Path, formatValue(c.New))
+	case Removed:
+		return fmt.Sprintf("- %s: %s", c.Path, formatValue(c.Old))
+	}
+	return fmt.Sprintf("~ %s: %s => %s", c.Path, formatValue(c.Old), formatValue(c.New))
+}
+
+// FormatChanges returns the changes as text, one change per line.
+func FormatChanges(changes []Change) string {
+	var b strings.Builder
+	for _, c := range changes {
+		b.WriteString(c.String())
+		b.WriteString("\n")
+	}
+	return b.String()
+}
+
+// formatValue formats the value of a change as JSON, if possible.
+func formatValue(v interface{}) string {
+	if b, err := json.Marshal(v); err == nil {
+		return string(b)
+	}
+	return fmt.Sprint(v)
+}
+
+// DiffOption configures how documents are compared.
+type DiffOption func(*diffOptions)
+
+// diffOptions holds the configuration of a comparison.
+type diffOptions struct {
+	// matchBy is the selector of the key elements of slices are matched by,
+	// or nil if elements are compared index-wise.
+	matchBy *Selector
+}
+
+// newDiffOptions applies the options to the default configuration.
+func newDiffOptions(opts []DiffOption) *diffOptions {
+	o := &diffOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// MatchElementsBy matches the elements of slices by the value the selector
+// resolves to on them, e.g. `.id`, rather than by their index. Slices in
+// which an element has no key, or in which keys are not unique, are
+// compared index-wise.
+func MatchElementsBy(sel *Selector) DiffOption {
+	return func(o *diffOptions) {
+		o.matchBy = sel
+	}
+}
+
+// Diff returns the changes turning document a into document b. Maps and
+// structs are compared key by key, sorted, and slices element by element,
+// by index or by key with MatchElementsBy. Values of different types are
+// modified as a whole; numbers are equal if their values are, regardless of
+// their types, so documents decoded from different formats compare equal.
+//
+// Changes are ordered so that applying them in order turns a into b, as
+// required by JSON Patch (see JSONPatch): within a slice compared by index,
+// modified elements come first, then elements added in ascending order or
+// removed in descending order. Within a slice compared by key, removed
+// elements come first in descending order, then modified elements, then
+// added elements in ascending order. The path of a change is the index of
+// the element in a if it was removed and in b otherwise. Reordering matched
+// elements is not a change, so applying the changes to a yields b with its
+// matched elements in their order in a.
+//
+// Example usage:
+//
+//	Diff(
+//		map[string]interface{}{"replicas": 2, "name": "web"},
+//		map[string]interface{}{"replicas": 3},
+//	)
+//	// => []Change{
+//	//	{Op: Removed, Path: `.name`, Old: "web"},
+//	//	{Op: Modified, Path: `.replicas`, Old: 2, New: 3},
+//	// }
+func Diff(a, b interface{}, opts ...DiffOption) []Change {
+	d := &differ{opts: newDiffOptions(opts)}
+	d.diff(nil, nil, decodeYAMLValue(a), decodeYAMLValue(b))
+	return d.changes
+}
+
+// differ compares documents, collecting the changes.
+type differ struct {
+	opts    *diffOptions
+	changes []Change
+}
+
+// diff compares the values at the path of keys. patchKeys is the path of
+// the values once the changes before them are applied.
+func (d *differ) diff(keys, patchKeys []interface{}, a, b interface{}) {
+	switch {
+	case isSequence(a) && isSequence(b):
+		d.diffSequences(keys, patchKeys, entries(a), entries(b))
+
+	case isContainer(a) && isContainer(b) && !isSequence(a) && !isSequence(b):
+		d.diffMaps(keys, patchKeys, entries(a), entries(b))
+
+	case !valuesEqual(a, b):
+		d.push(Modified, keys, patchKeys, a, b)
+	}
+}
+
+// diffMaps compares the entries of maps or structs.
+func (d *differ) diffMaps(keys, patchKeys []interface{}, a, b []entry) {
+	bByKey := make(map[interface{}]interface{}, len(b))
+	for _, e := range b {
+		bByKey[e.key] = e.val
+	}
+	aByKey := make(map[interface{}]interface{}, len(a))
+	for _, e := range a {
+		aByKey[e.key] = e.val
+	}
+
+	// entries are sorted, so keys are compared in order.
+	for _, key := range mergeSortedEntryKeys(a, b) {
+		av, inA := aByKey[key]
+		bv, inB := bByKey[key]
+		path, patchPath := appendKey(keys, key), appendKey(patchKeys, key)
+
+		switch {
+		case !inB:
+			d.push(Removed, path, patchPath, av, nil)
+		case !inA:
+			d.push(Added, path, patchPath, nil, bv)
+		default:
+			d.diff(path, patchPath, av, bv)
+		}
+	}
+}
+
+// diffSequences compares the elements of slices.
+func (d *differ) diffSequences(keys, patchKeys []interface{}, a, b []entry) {
+	if d.opts.matchBy != nil {
+		aKeys, aOK := d.elementKeys(a)
+		bKeys, bOK := d.elementKeys(b)
+		if aOK && bOK {
+			d.diffKeyedSequences(keys, patchKeys, a, b, aKeys, bKeys)
+			return
+		}
+	}
+
+	for i := 0; i < len(a) && i < len(b); i++ {
+		d.diff(appendKey(keys, i), appendKey(patchKeys, i), a[i].val, b[i].val)
+	}
+	for i := len(a); i < len(b); i++ {
+		d.push(Added, appendKey(keys, i), appendKey(patchKeys, i), nil, b[i].val)
+	}
+	for i := len(a) - 1; i >= len(b); i-- {
+		d.push(Removed, appendKey(keys, i), appendKey(patchKeys, i), a[i].val, nil)
+	}
+}
+
+// diffKeyedSequences compares the elements of slices matched by their
+// keys.
+func (d *differ) diffKeyedSequences(keys, patchKeys []interface{}, a, b []entry, aKeys, bKeys []string) {
+	aIndex := make(map[string]int, len(a))
+	for i, k := range aKeys {
+		aIndex[k] = i
+	}
+	bIndex := make(map[string]int, len(b))
+	for i, k := range bKeys {
+		bIndex[k] = i
+	}
+
+	for i := len(a) - 1; i >= 0; i-- {
+		if _, ok := bIndex[aKeys[i]]; !ok {
+			d.push(Removed, appendKey(keys, i), appendKey(patchKeys, i), a[i].val, nil)
+		}
+	}
+
+	// matched elements keep their order in a, so they are patched at their
+	// index once removed elements are gone and before any are added.
+	i := 0
+	for j, k := range aKeys {
+		if bi, ok := bIndex[k]; ok {
+			d.diff(appendKey(keys, bi), appendKey(patchKeys, i), a[j].val, b[bi].val)
+			i++
+		}
+	}
+	for i, k := range bKeys {
+		if _, ok := aIndex[k]; !ok {
+			d.push(Added, appendKey(keys, i), appendKey(patchKeys, i), nil, b[i].val)
+		}
+	}
+}
+
+// elementKeys returns the keys of the elements of a slice. false is
+// returned if an element has no key or if keys are not unique.
+func (d *differ) elementKeys(es []entry) ([]string, bool) {
+	keys := make([]string, len(es))
+	seen := make(map[string]bool, len(es))
+	for i, e := range es {
+		v, err := d.opts.matchBy.Resolve(e.val)
+		if err != nil || v == nil {
+			return nil, false
+		}
+
+		k := keyString(v)
+		if seen[k] {
+			return nil, false
+		}
+		seen[k] = true
+		keys[i] = k
+	}
+	return keys, true
+}
+
+// push adds a change at the path of keys.
+func (d *differ) push(op ChangeOp, keys, patchKeys []interface{}, old, new interface{}) {
+	c := Change{
+		Op:   op,
+		Path: selectorFromKeys(keys),
+		Old:  old,
+		New:  new,
+	}
+	if !reflect.DeepEqual(keys, patchKeys) {
+		c.patchPath = selectorFromKeys(patchKeys)
+	}
+	d.changes = append(d.changes, c)
+}
+
+// mergeSortedEntryKeys returns the union of the keys of the sorted entries,
+// sorted.
+func mergeSortedEntryKeys(a, b []entry) []interface{} {
+	var keys []interface{}
+	i, j := 0, 0
+	for i < len(a) || j < len(b) {
+		switch {
+		case j == len(b) || (i < len(a) && entryKeyLess(a[i].key, b[j].key)):
+			keys = append(keys, a[i].key)
+			i++
+		case i == len(a) || entryKeyLess(b[j].key, a[i].key):
+			keys = append(keys, b[j].key)
+			j++
+		default:
+			keys = append(keys, a[i].key)
+			i++
+			j++
+		}
+	}
+	return keys
+}
+
+// entryKeyLess reports whether the key of an entry sorts before another, as
+// entries are sorted: integers first, then strings.
+func entryKeyLess(a, b interface{}) bool {
+	ai, aIsInt := a.(int)
+	bi, bIsInt := b.(int)
+	switch {
+	case aIsInt && bIsInt:
+		return ai < bi
+	case aIsInt != bIsInt:
+		return aIsInt
+	}
+	return a.(string) < b.(string)
+}
+
+// valuesEqual determines if two values are equal. Numbers are compared by
+// value regardless of their types.
+func valuesEqual(a, b interface{}) bool {
+	if a != nil && b != nil {
+		af, aIsNum := toFloat64(a, reflect.ValueOf(a))
+		bf, bIsNum := toFloat64(b, reflect.ValueOf(b))
+		if aIsNum && bIsNum {
+			return af == bf
+		}
+	}
+	return reflect.DeepEqual(a, b)
+}
+
+// keyString returns a string identifying the key of an element. Numbers
+// with equal values are identified by the same string.
+func keyString(v interface{}) string {
+	if f, ok := toFloat64(v, reflect.ValueOf(v)); ok {
+		return strconv.FormatFloat(f, 'g', -1, 64)
+	}
+	return fmt.Sprintf("%T:%v", v, v)
+}
+
+// decodeYAMLValue decodes yaml.v3 nodes to the values they represent. Other
+// values are returned as-is.
+func decodeYAMLValue(v interface{}) interface{} {
+	n, ok := v.(*yaml.Node)
+	if !ok {
+		return v
+	}
+
+	var decoded interface{}
+	if n = derefYAMLNode(n); n == nil || n.Decode(&decoded) != nil {
+		return nil
+	}
+	return decoded
+}
@@ -0,0 +1,217 @@
+package selectr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type diffTestFixture struct {
+	a, b     interface{}
+	opts     []DiffOption
+	expected []string
+}
+
+func runDiffTest(t *testing.T, fixture diffTestFixture) {
+	t.Helper()
+
+	changes := Diff(fixture.a, fixture.b, fixture.opts...)
+
+	var lines []string
+	for _, c := range changes {
+		lines = append(lines, c.String())
+	}
+	if diff := cmp.Diff(fixture.expected, lines); diff != "" {
+		t.Errorf("changes were not as expected:\n%s", diff)
+	}
+
+	// applying the changes in order to a copy of a turns it into b. Elements
+	// matched by key may be added within a slice, which Set cannot do.
+	if fixture.opts != nil {
+		return
+	}
+	doc, err := Unflatten(Flatten(fixture.a))
+	if err != nil {
+		t.Fatalf("could not copy document: %s", err)
+	}
+	for _, c := range changes {
+		if c.Op == Removed {
+			doc, err = c.Path.Delete(doc)
+		} else {
+			doc, err = c.Path.Set(doc, c.New)
+		}
+		if err != nil {
+			t.Fatalf("could not apply change `%s`: %s", c, err)
+		}
+	}
+	if remaining := Diff(doc, fixture.b); len(remaining) != 0 {
+		t.Errorf("applying the changes did not produce the new document:\n%s", FormatChanges(remaining))
+	}
+}
+
+func TestDiff(t *testing.T) {
+	a := map[string]interface{}{
+		"name":     "web",
+		"replicas": 2,
+		"ports":    []interface{}{80, 443, 8080},
+		"labels":   map[string]interface{}{"app": "web", "tier": "frontend"},
+		"env":      []interface{}{"A=1"},
+	}
+	b := map[string]interface{}{
+		"replicas": float64(3),
+		"ports":    []interface{}{80},
+		"labels":   map[string]interface{}{"app": "web", "team": "core"},
+		"env":      []interface{}{"A=2", "B=1", nil},
+		"image":    "web:1.0",
+	}
+
+	runDiffTest(t, diffTestFixture{
+		a: a,
+		b: b,
+		expected: []string{
+			`~ .env[0]: "A=1" => "A=2"`,
+			`+ .env[1]: "B=1"`,
+			`+ .env[2]: null`,
+			`+ .image: "web:1.0"`,
+			`+ .labels.team: "core"`,
+			`- .labels.tier: "frontend"`,
+			`- .name: "web"`,
+			`- .ports[2]: 8080`,
+			`- .ports[1]: 443`,
+			`~ .replicas: 2 => 3`,
+		},
+	})
+
+	// numbers are equal regardless of their types.
+	runDiffTest(t, diffTestFixture{
+		a: map[string]interface{}{"port": 80},
+		b: map[string]interface{}{"port": float64(80)},
+	})
+
+	runDiffTest(t, diffTestFixture{
+		a:        map[string]interface{}{"a": map[string]interface{}{"b": 1}},
+		b:        map[string]interface{}{"a": []interface{}{1}},
+		expected: []string{`~ .a: {"b":1} => [1]`},
+	})
+
+	runDiffTest(t, diffTestFixture{
+		a:        "a",
+		b:        "b",
+		expected: []string{`~ : "a" => "b"`},
+	})
+}
+
+func TestDiff_matchElementsBy(t *testing.T) {
+	id, _ := Parse(".id")
+
+	a := []interface{}{
+		map[string]interface{}{"id": 1, "name": "a"},
+		map[string]interface{}{"id": 2, "name": "b"},
+		map[string]interface{}{"id": 3, "name": "c"},
+	}
+	b := []interface{}{
+		map[string]interface{}{"id": 0, "name": "z"},
+		map[string]interface{}{"id": 1, "name": "a"},
+		map[string]interface{}{"id": 3, "name": "C"},
+	}
+
+	runDiffTest(t, diffTestFixture{
+		a:    a,
+		b:    b,
+		opts: []DiffOption{MatchElementsBy(id)},
+		expected: []string{
+			`- [1]: {"id":2,"name":"b"}`,
+			`~ [2].name: "c" => "C"`,
+			`+ [0]: {"id":0,"name":"z"}`,
+		},
+	})
+
+	// matched elements are modified at their index in b.
+	runDiffTest(t, diffTestFixture{
+		a: []interface{}{
+			map[string]interface{}{"id": 1, "v": 1},
+			map[string]interface{}{"id": 2, "v": 2},
+		},
+		b: []interface{}{
+			map[string]interface{}{"id": 2, "v": 2},
+			map[string]interface{}{"id": 1, "v": 9},
+		},
+		opts:     []DiffOption{MatchElementsBy(id)},
+		expected: []string{`~ [1].v: 1 => 9`},
+	})
+
+	// elements without keys are compared index-wise.
+	runDiffTest(t, diffTestFixture{
+		a:        []interface{}{map[string]interface{}{"id": 1}, "x"},
+		b:        []interface{}{map[string]interface{}{"id": 1}, "y"},
+		opts:     []DiffOption{MatchElementsBy(id)},
+		expected: []string{`~ [1]: "x" => "y"`},
+	})
+}
+
+func TestDiff_yaml(t *testing.T) {
+	var a, b yaml.Node
+	yaml.Unmarshal([]byte("replicas: 2\nimage: web:1.0\n"), &a)
+	yaml.Unmarshal([]byte("replicas: 3\nimage: web:1.0\n"), &b)
+
+	if diff := cmp.Diff("~ .replicas: 2 => 3\n", FormatChanges(Diff(&a, &b))); diff != "" {
+		t.Errorf("changes were not as expected:\n%s", diff)
+	}
+}
+
+func TestJSONPatch(t *testing.T) {
+	changes := Diff(
+		map[string]interface{}{"a/b": 1, "list": []interface{}{1, 2}, "gone": true},
+		map[string]interface{}{"a/b": 2, "list": []interface{}{1, 2, nil}},
+	)
+
+	b, err := json.Marshal(JSONPatch(changes))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `[{"op":"replace","path":"/a~1b","value":2},` +
+		`{"op":"remove","path":"/gone"},` +
+		`{"op":"add","path":"/list/2","value":null}]`
+	if diff := cmp.Diff(expected, string(b)); diff != "" {
+		t.Errorf("JSON Patch was not as expected:\n%s", diff)
+	}
+}
+
+func TestJSONPatch_matchElementsBy(t *testing.T) {
+	id, _ := Parse(".id")
+
+	var a, b interface{}
+	json.Unmarshal([]byte(`{"l": [{"id": 1}, {"id": 2}, {"id": 3, "v": 3}]}`), &a)
+	json.Unmarshal([]byte(`{"l": [{"id": 3, "v": 30}, {"id": 4}, {"id": 1}]}`), &b)
+
+	// changes are at the index of the element in a or b, while the patch
+	// addresses elements once the operations before it are applied.
+	changes := Diff(a, b, MatchElementsBy(id))
+	expected := "- .l[1]: {\"id\":2}\n~ .l[0].v: 3 => 30\n+ .l[1]: {\"id\":4}\n"
+	if diff := cmp.Diff(expected, FormatChanges(changes)); diff != "" {
+		t.Errorf("changes were not as expected:\n%s", diff)
+	}
+
+	patch, _ := json.Marshal(JSONPatch(changes))
+	expectedPatch := `[{"op":"remove","path":"/l/1"},` +
+		`{"op":"replace","path":"/l/1/v","value":30},` +
+		`{"op":"add","path":"/l/1","value":{"id":4}}]`
+	if diff := cmp.Diff(expectedPatch, string(patch)); diff != "" {
+		t.Errorf("JSON Patch was not as expected:\n%s", diff)
+	}
+
+	// the patch of elements kept in order turns a into b.
+	json.Unmarshal([]byte(`{"l": [{"id": 1}, {"id": 2}, {"id": 3, "v": 3}, {"id": 4, "v": 4}]}`), &a)
+	json.Unmarshal([]byte(`{"l": [{"id": 0}, {"id": 3, "v": 30}, {"id": 4, "v": 40}, {"id": 5}]}`), &b)
+
+	result, err := ApplyPatch(a, JSONPatch(Diff(a, b, MatchElementsBy(id))))
+	if err != nil {
+		t.Fatalf("could not apply patch: %s", err)
+	}
+	if diff := cmp.Diff(b, result); diff != "" {
+		t.Errorf("patch was not applied as expected:\n%s", diff)
+	}
+}
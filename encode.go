@@ -0,0 +1,196 @@
+package selectr
+
+import (
+	"fmt"
+	"reflect"
+	"strconv"
+)
+
+// EncodeError represents the errors encoding the fields of a struct.
+type EncodeError []*FieldError
+
+// EncodeError implements (error).Error
+func (l EncodeError) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+	return fmt.Sprintf("%s (and %d more errors)", l[0], len(l)-1)
+}
+
+// Push adds a new field error to the collection.
+func (l *EncodeError) Push(err *FieldError) {
+	*l = append(*l, err)
+}
+
+// Encode builds a document from the struct (or pointer to a struct) by
+// setting the value of each field tagged with a selector at its selector,
+// creating intermediate maps and slices as needed. It is the inverse of
+// Decode, and follows the same tags:
+//
+//	type Deployment struct {
+//		Name  string `selectr:".metadata.name"`
+//		Image string `selectr:".spec.containers[0].image"`
+//	}
+//
+//	doc, _ := Encode(Deployment{Name: "web", Image: "web:1.0"})
+//	// => map[string]interface{}{
+//	//	"metadata": map[string]interface{}{"name": "web"},
+//	//	"spec": map[string]interface{}{"containers": []interface{}{
+//	//		map[string]interface{}{"image": "web:1.0"},
+//	//	}},
+//	// }
+//
+// Fields holding structs or pointers to structs are encoded field by field
+// under their selector, and fields holding slices of structs element by
+// element; nil pointers to structs are omitted. Untagged struct fields are
+// encoded under the selector of the struct holding them. All other values
+// are set as-is. The `required` and `default` tag options are ignored.
+//
+// Fields whose selectors overlap, e.g. `.a` and `.a.b`, conflict as one
+// would overwrite the other. All fields are encoded before returning; the
+// errors of the fields that could not be encoded, including conflicts, are
+// returned as an EncodeError.
+func Encode(src interface{}) (map[string]interface{}, error) {
+	rv := indirect(reflect.ValueOf(src))
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("cannot encode %T; expected a struct or a pointer to a struct", src)
+	}
+
+	e := &encoder{doc: map[string]interface{}{}}
+	e.encodeStruct(rv, "", "")
+	if len(e.errs) != 0 {
+		return nil, e.errs
+	}
+	return e.doc.(map[string]interface{}), nil
+}
+
+// encodedValue represents a value set by the encoder.
+type encodedValue struct {
+	field    string
+	selector string
+	segments []string
+}
+
+// encoder encodes structs into documents, collecting the errors of the
+// fields.
+type encoder struct {
+	doc  interface{}
+	set  []encodedValue
+	errs EncodeError
+}
+
+// encodeStruct encodes the fields of the struct under the canonical
+// selector prefix.
+func (e *encoder) encodeStruct(rv reflect.Value, prefix, path string) {
+	t := rv.Type()
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if f.PkgPath != "" && !f.Anonymous {
+			// the field is unexported.
+			continue
+		}
+
+		field := f.Name
+		if path != "" {
+			field = path + "." + f.Name
+		}
+
+		tag, ok := f.Tag.Lookup(tagName)
+		if !ok {
+			if fv := rv.Field(i); isDecodableStruct(f.Type) {
+				if fv = indirect(fv); fv.IsValid() {
+					e.encodeStruct(fv, prefix, field)
+				}
+			}
+			continue
+		}
+		if tag == "-" {
+			continue
+		}
+
+		fail := func(err error) {
+			e.errs.Push(&FieldError{Field: field, Selector: tag, Err: err})
+		}
+
+		ft, err := parseFieldTag(tag)
+		if err != nil {
+			fail(err)
+			continue
+		}
+		sel, err := Parse(ft.selector)
+		if err != nil {
+			fail(err)
+			continue
+		}
+
+		e.encodeValue(rv.Field(i), prefix+sel.String(), field, ft.selector)
+	}
+}
+
+// encodeValue encodes the value at the canonical selector.
+func (e *encoder) encodeValue(rv reflect.Value, selector, field, tagSelector string) {
+	t := rv.Type()
+
+	switch {
+	case isDecodableStruct(t):
+		if rv = indirect(rv); rv.IsValid() {
+			e.encodeStruct(rv, selector, field)
+		}
+
+	case t.Kind() == reflect.Slice && isDecodableStruct(t.Elem()) && rv.Len() != 0:
+		for i := 0; i < rv.Len(); i++ {
+			index := "[" + strconv.Itoa(i) + "]"
+			e.encodeValue(rv.Index(i), selector+index, field+index, tagSelector)
+		}
+
+	default:
+		if err := e.setValue(selector, field, rv.Interface()); err != nil {
+			e.errs.Push(&FieldError{Field: field, Selector: tagSelector, Err: err})
+		}
+	}
+}
+
+// setValue sets the value of the field at the canonical selector on the
+// document, unless it overlaps with a value set before.
+func (e *encoder) setValue(selector, field string, v interface{}) error {
+	sel, err := Parse(selector)
+	if err != nil {
+		return err
+	}
+
+	segments := sel.segments()
+	if len(segments) == 0 {
+		return fmt.Errorf("cannot encode a value at the root of the document")
+	}
+
+	for _, prev := range e.set {
+		if hasSegmentPrefix(prev.segments, segments) || hasSegmentPrefix(segments, prev.segments) {
+			return fmt.Errorf("'%s' conflicts with '%s' of field %s", selector, prev.selector, prev.field)
+		}
+	}
+
+	doc, err := sel.Set(e.doc, v)
+	if err != nil {
+		return err
+	}
+	e.doc = doc
+	e.set = append(e.set, encodedValue{field: field, selector: selector, segments: segments})
+	return nil
+}
+
+// hasSegmentPrefix determines if the segments begin with the prefix.
+func hasSegmentPrefix(segments, prefix []string) bool {
+	if len(prefix) > len(segments) {
+		return false
+	}
+	for i := range prefix {
+		if segments[i] != prefix[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,108 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type encodeTestContainer struct {
+	Name  string `selectr:".name"`
+	Image string `selectr:".image"`
+}
+
+type encodeTestMetadata struct {
+	Name string `selectr:".metadata.name"`
+}
+
+type encodeTestDeployment struct {
+	encodeTestMetadata
+
+	Replicas   int                    `selectr:".spec.replicas,default=1"`
+	Containers []encodeTestContainer  `selectr:".spec.template.spec.containers"`
+	Strategy   *encodeTestContainer   `selectr:".spec.strategy"`
+	Owner      *encodeTestContainer   `selectr:".metadata.owner"`
+	Labels     map[string]interface{} `selectr:".metadata.labels"`
+	Port       int                    `selectr:"['content-ports'][1]"`
+	Ignored    string                 `selectr:"-"`
+	Untagged   string
+}
+
+func TestEncode(t *testing.T) {
+	result, err := Encode(&encodeTestDeployment{
+		encodeTestMetadata: encodeTestMetadata{Name: "web"},
+		Replicas:           3,
+		Containers: []encodeTestContainer{
+			{Name: "app", Image: "web:1.0"},
+			{Name: "sidecar", Image: "proxy:2.1"},
+		},
+		Strategy: &encodeTestContainer{Name: "rolling"},
+		Labels:   map[string]interface{}{"app": "web"},
+		Port:     8080,
+		Ignored:  "ignored",
+		Untagged: "ignored",
+	})
+	if err != nil {
+		t.Fatalf("could not encode: %s", err)
+	}
+
+	expected := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"name":   "web",
+			"labels": map[string]interface{}{"app": "web"},
+		},
+		"spec": map[string]interface{}{
+			"replicas": 3,
+			"template": map[string]interface{}{"spec": map[string]interface{}{
+				"containers": []interface{}{
+					map[string]interface{}{"name": "app", "image": "web:1.0"},
+					map[string]interface{}{"name": "sidecar", "image": "proxy:2.1"},
+				},
+			}},
+			"strategy": map[string]interface{}{"name": "rolling", "image": ""},
+		},
+		"content-ports": []interface{}{nil, 8080},
+	}
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("struct was not encoded as expected:\n%s", diff)
+	}
+
+	// encoding is the inverse of decoding.
+	var decoded encodeTestDeployment
+	if err := Decode(result, &decoded); err != nil {
+		t.Fatalf("could not decode the encoded document: %s", err)
+	}
+	if decoded.Containers[1].Image != "proxy:2.1" || decoded.Port != 8080 {
+		t.Errorf("encoded document was not decoded as expected: %+v", decoded)
+	}
+}
+
+func TestEncode_conflict(t *testing.T) {
+	_, err := Encode(struct {
+		A   map[string]interface{} `selectr:".a"`
+		AB  string                 `selectr:".a.b"`
+		C   string                 `selectr:"['c'][0]"`
+		C0  string                 `selectr:".c[0]"`
+		D   string                 `selectr:".d[0]"`
+		D1  string                 `selectr:".d[1]"`
+		Bad string                 `selectr:".e,omitempty"`
+	}{})
+
+	var messages []string
+	for _, fieldErr := range err.(EncodeError) {
+		messages = append(messages, fieldErr.Error())
+	}
+
+	expected := []string{
+		"AB: '.a.b' conflicts with '.a' of field A",
+		"C0: '.c[0]' conflicts with '.c[0]' of field C",
+		"Bad: unknown tag option 'omitempty'",
+	}
+	if diff := cmp.Diff(expected, messages); diff != "" {
+		t.Errorf("errors for encoding were not as expected:\n%s", diff)
+	}
+
+	if _, err := Encode("not a struct"); err == nil {
+		t.Error("expected an error encoding a string")
+	}
+}
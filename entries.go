@@ -0,0 +1,207 @@
+package selectr
+
+import (
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// entry represents a key or index of a container and its value.
+type entry struct {
+	// key is a string for map keys and struct fields, and an int for slice
+	// indices and integer map keys.
+	key interface{}
+	val interface{}
+}
+
+// entries returns the entries of a map, slice, struct or yaml.v3 node in a
+// deterministic order: indices ascending, then keys sorted. Keys that
+// cannot be selected are omitted. nil is returned for any other value.
+func entries(v interface{}) []entry {
+	var es []entry
+
+	switch v := v.(type) {
+	case map[string]interface{}:
+		for k, val := range v {
+			es = append(es, entry{key: k, val: val})
+		}
+
+	case map[interface{}]interface{}:
+		for k, val := range v {
+			if n, ok := intKey(k); ok {
+				es = append(es, entry{key: int(n), val: val})
+			} else if b, ok := k.(bool); ok {
+				es = append(es, entry{key: strconv.FormatBool(b), val: val})
+			} else if s, ok := k.(string); ok {
+				es = append(es, entry{key: s, val: val})
+			}
+		}
+
+	case []interface{}:
+		for i, val := range v {
+			es = append(es, entry{key: i, val: val})
+		}
+		return es
+
+	case *yaml.Node:
+		n := derefYAMLNode(v)
+		if n == nil {
+			return nil
+		}
+		switch n.Kind {
+		case yaml.SequenceNode:
+			for i, val := range n.Content {
+				es = append(es, entry{key: i, val: derefYAMLNode(val)})
+			}
+			return es
+
+		case yaml.MappingNode:
+			for _, k := range yamlMappingKeys(n) {
+				key := interface{}(k.Value)
+				if k.ShortTag() == "!!int" {
+					if i, err := strconv.Atoi(k.Value); err == nil {
+						key = i
+					}
+				}
+
+				matcher := yamlKeyMatcher(k.Value)
+				if i, ok := key.(int); ok {
+					matcher = yamlIntKeyMatcher(i)
+				}
+				val, _ := yamlMappingLookup(n, matcher)
+				es = append(es, entry{key: key, val: val})
+			}
+		}
+
+	default:
+		rv := indirect(reflect.ValueOf(v))
+		switch rv.Kind() {
+		case reflect.Slice, reflect.Array:
+			for i := 0; i < rv.Len(); i++ {
+				es = append(es, entry{key: i, val: rv.Index(i).Interface()})
+			}
+			return es
+
+		case reflect.Map:
+			if rv.Type().Key().Kind() != reflect.String {
+				return nil
+			}
+			iter := rv.MapRange()
+			for iter.Next() {
+				es = append(es, entry{key: iter.Key().String(), val: iter.Value().Interface()})
+			}
+
+		case reflect.Struct:
+			for _, f := range structFields(rv.Type()) {
+				if fv, ok := fieldByIndex(rv, f.index); ok {
+					es = append(es, entry{key: f.name, val: fv.Interface()})
+				}
+			}
+		}
+	}
+
+	sort.Slice(es, func(i, j int) bool {
+		ki, iIsInt := es[i].key.(int)
+		kj, jIsInt := es[j].key.(int)
+		switch {
+		case iIsInt && jIsInt:
+			return ki < kj
+		case iIsInt != jIsInt:
+			return iIsInt
+		}
+		return es[i].key.(string) < es[j].key.(string)
+	})
+	return es
+}
+
+// indirect dereferences pointers and interfaces until it reaches a
+// concrete value. The zero Value is returned for nil pointers.
+func indirect(rv reflect.Value) reflect.Value {
+	for rv.Kind() == reflect.Ptr || rv.Kind() == reflect.Interface {
+		if rv.IsNil() {
+			return reflect.Value{}
+		}
+		rv = rv.Elem()
+	}
+	return rv
+}
+
+// structField represents a selectable field of a struct.
+type structField struct {
+	// name is the name of the field in its `json` tag, or the Go name of
+	// the field if it has none.
+	name string
+
+	// goName is the Go name of the field.
+	goName string
+
+	// index is the index sequence of the field for
+	// (reflect.Value).FieldByIndex.
+	index []int
+}
+
+// structFields returns the exported fields of the struct type. Fields of
+// embedded structs without a `json` name are promoted, as they are by
+// encoding/json. Fields tagged `json:"-"` are omitted.
+func structFields(t reflect.Type) []structField {
+	var fields []structField
+
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+
+		name := f.Name
+		if tag, ok := f.Tag.Lookup("json"); ok {
+			if tag == "-" {
+				continue
+			}
+			if tagName := strings.Split(tag, ",")[0]; tagName != "" {
+				name = tagName
+			}
+		}
+
+		if f.Anonymous && name == f.Name {
+			ft := f.Type
+			if ft.Kind() == reflect.Ptr {
+				ft = ft.Elem()
+			}
+			if ft.Kind() == reflect.Struct {
+				for _, embedded := range structFields(ft) {
+					embedded.index = append([]int{i}, embedded.index...)
+					fields = append(fields, embedded)
+				}
+				continue
+			}
+		}
+
+		if f.PkgPath != "" {
+			// the field is unexported.
+			continue
+		}
+
+		fields = append(fields, structField{
+			name:   name,
+			goName: f.Name,
+			index:  []int{i},
+		})
+	}
+
+	return fields
+}
+
+// fieldByIndex returns the nested field of the struct value with the index
+// sequence. false is returned if an embedded struct pointer along the way
+// is nil.
+func fieldByIndex(rv reflect.Value, index []int) (reflect.Value, bool) {
+	for i, x := range index {
+		if i > 0 {
+			if rv = indirect(rv); !rv.IsValid() {
+				return reflect.Value{}, false
+			}
+		}
+		rv = rv.Field(x)
+	}
+	return rv, true
+}
@@ -0,0 +1,160 @@
+package selectr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// PathStyle is the notation of the paths of a flattened document.
+type PathStyle int
+
+const (
+	// StyleKeyPath writes paths as canonical selectors, e.g.
+	// `.accounts[0]['content-type']`. It round-trips exactly.
+	StyleKeyPath PathStyle = iota
+
+	// StyleDotted writes paths as keys and indices joined by dots, e.g.
+	// `accounts.0.content-type`, as commonly used for environment-style
+	// configuration. Keys containing dots and keys consisting of digits do
+	// not round-trip.
+	StyleDotted
+
+	// StyleJSONPointer writes paths as JSON Pointers (RFC 6901), e.g.
+	// `/accounts/0/content-type`. Keys consisting of digits do not
+	// round-trip.
+	StyleJSONPointer
+)
+
+// FlattenOption configures how a document is flattened or unflattened.
+type FlattenOption func(*flattenOptions)
+
+// flattenOptions holds the configuration of flattening a document.
+type flattenOptions struct {
+	style PathStyle
+}
+
+// newFlattenOptions applies the options to the default configuration.
+func newFlattenOptions(opts []FlattenOption) *flattenOptions {
+	o := &flattenOptions{style: StyleKeyPath}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// WithPathStyle sets the notation of the paths of a flattened document. The
+// default is StyleKeyPath.
+func WithPathStyle(style PathStyle) FlattenOption {
+	return func(o *flattenOptions) {
+		o.style = style
+	}
+}
+
+// Flatten returns the leaves of the document keyed by their path, as
+// visited by Walk. Empty maps and slices are kept as leaves so that the
+// structure can be rebuilt by Unflatten. yaml.v3 scalar nodes are decoded
+// to their values.
+//
+// Example usage:
+//
+//	Flatten(map[string]interface{}{"a": map[string]interface{}{"b": []interface{}{1}}})
+//	// => map[string]interface{}{".a.b[0]": 1}
+func Flatten(doc interface{}, opts ...FlattenOption) map[string]interface{} {
+	o := newFlattenOptions(opts)
+
+	flat := map[string]interface{}{}
+	Walk(doc, func(sel *Selector, v interface{}) error {
+		flat[o.format(sel)] = decodeYAMLValue(v)
+		return nil
+	})
+	return flat
+}
+
+// Unflatten rebuilds the nested document from the values keyed by their
+// path, creating maps for keys and slices for indices. Paths are parsed in
+// the style of the options; with StyleDotted and StyleJSONPointer, path
+// segments consisting of digits are slice indices.
+//
+// An error is returned if a path cannot be parsed or if paths conflict,
+// i.e. if a value would be set within or in place of another, as with
+// `.a` and `.a.b`.
+func Unflatten(flat map[string]interface{}, opts ...FlattenOption) (interface{}, error) {
+	o := newFlattenOptions(opts)
+
+	type flatValue struct {
+		path     string
+		sel      *Selector
+		segments []string
+	}
+
+	values := make([]flatValue, 0, len(flat))
+	for path := range flat {
+		sel, err := o.parse(path)
+		if err != nil {
+			return nil, fmt.Errorf("invalid path '%s': %w", path, err)
+		}
+
+		values = append(values, flatValue{path: path, sel: sel, segments: sel.segments()})
+	}
+
+	// paths within another path sort directly after it.
+	sort.Slice(values, func(i, j int) bool {
+		a, b := values[i].segments, values[j].segments
+		for k := 0; k < len(a) && k < len(b); k++ {
+			if a[k] != b[k] {
+				return a[k] < b[k]
+			}
+		}
+		if len(a) != len(b) {
+			return len(a) < len(b)
+		}
+		return values[i].path < values[j].path
+	})
+
+	var doc interface{}
+	for i, v := range values {
+		if i > 0 && hasSegmentPrefix(v.segments, values[i-1].segments) {
+			return nil, fmt.Errorf("path '%s' conflicts with '%s'", v.path, values[i-1].path)
+		}
+
+		var err error
+		if doc, err = v.sel.Set(doc, flat[v.path]); err != nil {
+			return nil, fmt.Errorf("cannot set path '%s': %w", v.path, err)
+		}
+	}
+	return doc, nil
+}
+
+// format returns the path of the selector in the style of the options.
+func (o *flattenOptions) format(sel *Selector) string {
+	switch o.style {
+	case StyleDotted:
+		var keys []string
+		for _, key := range sel.keys() {
+			keys = append(keys, fmt.Sprint(key))
+		}
+		return strings.Join(keys, ".")
+	case StyleJSONPointer:
+		return sel.JSONPointer()
+	}
+	return sel.String()
+}
+
+// parse parses the path in the style of the options.
+func (o *flattenOptions) parse(path string) (*Selector, error) {
+	switch o.style {
+	case StyleDotted:
+		if path == "" {
+			return &Selector{}, nil
+		}
+		var keys []interface{}
+		for _, token := range strings.Split(path, ".") {
+			keys = append(keys, tokenKey(token))
+		}
+		return selectorFromKeys(keys), nil
+	case StyleJSONPointer:
+		return ParseJSONPointer(path)
+	}
+	return Parse(path)
+}
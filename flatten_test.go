@@ -0,0 +1,149 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type flattenTestFixture struct {
+	style    PathStyle
+	val      interface{}
+	expected map[string]interface{}
+}
+
+func runFlattenTest(t *testing.T, fixture flattenTestFixture) {
+	t.Helper()
+
+	flat := Flatten(fixture.val, WithPathStyle(fixture.style))
+	if diff := cmp.Diff(fixture.expected, flat); diff != "" {
+		t.Errorf("document was not flattened as expected:\n%s", diff)
+	}
+
+	doc, err := Unflatten(flat, WithPathStyle(fixture.style))
+	if err != nil {
+		t.Errorf("could not unflatten document: %s", err)
+		return
+	}
+	if diff := cmp.Diff(fixture.val, doc); diff != "" {
+		t.Errorf("document did not round-trip:\n%s", diff)
+	}
+}
+
+func TestFlatten(t *testing.T) {
+	val := map[string]interface{}{
+		"accounts": []interface{}{
+			map[string]interface{}{"name": "main", "tags": []interface{}{}},
+			nil,
+			map[string]interface{}{"content-type": "json"},
+		},
+		"a/b~c":    1,
+		"settings": map[string]interface{}{},
+	}
+
+	runFlattenTest(t, flattenTestFixture{
+		style: StyleKeyPath,
+		val:   val,
+		expected: map[string]interface{}{
+			".accounts[0].name":            "main",
+			".accounts[0].tags":            []interface{}{},
+			".accounts[1]":                 nil,
+			".accounts[2]['content-type']": "json",
+			"['a/b~c']":                    1,
+			".settings":                    map[string]interface{}{},
+		},
+	})
+
+	runFlattenTest(t, flattenTestFixture{
+		style: StyleDotted,
+		val:   val,
+		expected: map[string]interface{}{
+			"accounts.0.name":         "main",
+			"accounts.0.tags":         []interface{}{},
+			"accounts.1":              nil,
+			"accounts.2.content-type": "json",
+			"a/b~c":                   1,
+			"settings":                map[string]interface{}{},
+		},
+	})
+
+	runFlattenTest(t, flattenTestFixture{
+		style: StyleJSONPointer,
+		val:   val,
+		expected: map[string]interface{}{
+			"/accounts/0/name":         "main",
+			"/accounts/0/tags":         []interface{}{},
+			"/accounts/1":              nil,
+			"/accounts/2/content-type": "json",
+			"/a~1b~0c":                 1,
+			"/settings":                map[string]interface{}{},
+		},
+	})
+
+	runFlattenTest(t, flattenTestFixture{
+		val:      []interface{}{"a", "b"},
+		expected: map[string]interface{}{"[0]": "a", "[1]": "b"},
+	})
+
+	runFlattenTest(t, flattenTestFixture{
+		val:      "scalar",
+		expected: map[string]interface{}{"": "scalar"},
+	})
+}
+
+func TestFlatten_yaml(t *testing.T) {
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("server:\n  port: 8080\n  hosts: [a, b]\n"), &doc)
+
+	expected := map[string]interface{}{
+		".server.port":     8080,
+		".server.hosts[0]": "a",
+		".server.hosts[1]": "b",
+	}
+	if diff := cmp.Diff(expected, Flatten(&doc)); diff != "" {
+		t.Errorf("document was not flattened as expected:\n%s", diff)
+	}
+}
+
+func TestUnflatten_error(t *testing.T) {
+	for _, fixture := range []struct {
+		style PathStyle
+		flat  map[string]interface{}
+		err   string
+	}{
+		{
+			flat: map[string]interface{}{".a": 1, ".a.b": 2},
+			err:  "path '.a.b' conflicts with '.a'",
+		},
+		{
+			flat: map[string]interface{}{".a": 1, "['a']": 2},
+			err:  "path '['a']' conflicts with '.a'",
+		},
+		{
+			flat: map[string]interface{}{"": 1, ".b": 2},
+			err:  "path '.b' conflicts with ''",
+		},
+		{
+			style: StyleDotted,
+			flat:  map[string]interface{}{"a.0": 1, "a.0.b": 2},
+			err:   "path 'a.0.b' conflicts with 'a.0'",
+		},
+		{
+			flat: map[string]interface{}{".a[": 1},
+			err:  "invalid path '.a[': unexpected end of selector",
+		},
+		{
+			style: StyleJSONPointer,
+			flat:  map[string]interface{}{"a/b": 1},
+			err:   "invalid path 'a/b': JSON Pointer must begin with /",
+		},
+	} {
+		_, err := Unflatten(fixture.flat, WithPathStyle(fixture.style))
+		if err == nil {
+			t.Errorf("expected error '%s' but got none", fixture.err)
+		} else if err.Error() != fixture.err {
+			t.Errorf("expected error '%s' but got '%s'", fixture.err, err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package selectr
+
+import (
+	"reflect"
+	"time"
+)
+
+// Get resolves the value at the selector from the document and converts it
+// to T. Values of type T are returned as-is; otherwise the value is
+// converted by the following rules:
+//
+//   - yaml.v3 nodes are decoded first, unless T is *yaml.Node.
+//   - integers, floats and json.Numbers convert to integer types only if
+//     they hold an integer in range of the type, e.g. 3.0 converts to int but
+//     3.5 does not. They convert to float types as-is.
+//   - strings convert to time.Duration as parsed by time.ParseDuration.
+//   - strings convert to time.Time as parsed in the RFC 3339 format.
+//   - strings and booleans convert to defined types of the same kind.
+//   - slices and arrays convert to slices of T element-wise.
+//   - nil, e.g. for a missing key, converts to the zero value of
+//     interfaces, pointers, slices and maps.
+//
+// A ResolveError positioned at the last expression of the selector is
+// returned if the value cannot be converted. Its code is "TypeError" if
+// the type of the value cannot be converted and "ValueError" if the value
+// itself cannot be, e.g. an inexact float or an invalid duration.
+//
+// Example usage:
+//
+//	sel, _ := Parse(".timeout")
+//	timeout, err := Get[time.Duration](sel, map[string]interface{}{"timeout": "5s"})
+//	// => 5 * time.Second, nil
+func Get[T any](sel *Selector, doc interface{}, opts ...ResolveOption) (T, error) {
+	v, err := sel.Resolve(doc, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return convertTo[T](sel, v)
+}
+
+// convertTo converts the value resolved by the selector to T, as by Get.
+func convertTo[T any](sel *Selector, v interface{}) (T, error) {
+	var out T
+
+	rv, err := sel.convert(v, reflect.TypeOf(&out).Elem())
+	if err != nil {
+		return out, err
+	}
+
+	// the value is set by reflection, as a nil interface cannot be asserted
+	// to T if T is an interface type.
+	reflect.ValueOf(&out).Elem().Set(rv)
+	return out, nil
+}
+
+// convert converts the value resolved by the selector to the type t. A
+// ResolveError positioned at the last expression of the selector is
+// returned if it cannot be converted.
+func (s *Selector) convert(v interface{}, t reflect.Type) (reflect.Value, error) {
+	rv, err := convert(v, t)
+	if err != nil {
+		convErr := err.(conversionError)
+		return reflect.Value{}, ResolveError{
+			Code: convErr.code,
+			Msg:  convErr.msg,
+			Pos:  s.lastPos(),
+			Path: s.String(),
+		}
+	}
+	return rv, nil
+}
+
+// GetString resolves the string at the selector from the document. See Get.
+func GetString(sel *Selector, doc interface{}, opts ...ResolveOption) (string, error) {
+	return Get[string](sel, doc, opts...)
+}
+
+// GetInt resolves the integer at the selector from the document. Floats
+// holding an integer, as decoded by encoding/json, are converted. See Get.
+func GetInt(sel *Selector, doc interface{}, opts ...ResolveOption) (int, error) {
+	return Get[int](sel, doc, opts...)
+}
+
+// GetDuration resolves the duration at the selector from the document.
+// Strings are parsed by time.ParseDuration. See Get.
+func GetDuration(sel *Selector, doc interface{}, opts ...ResolveOption) (time.Duration, error) {
+	return Get[time.Duration](sel, doc, opts...)
+}
+
+// GetTime resolves the time at the selector from the document. Strings are
+// parsed in the RFC 3339 format. See Get.
+func GetTime(sel *Selector, doc interface{}, opts ...ResolveOption) (time.Time, error) {
+	return Get[time.Time](sel, doc, opts...)
+}
+
+// lastPos returns the position of the last expression of the selector, or
+// 0 if the selector is empty.
+func (s *Selector) lastPos() int {
+	pos := 0
+	for curr := s.tree; curr != nil; curr = curr.Child {
+		pos = curr.Resolver.Expression().StartPos()
+	}
+	return pos
+}
@@ -0,0 +1,194 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type getTestFixture struct {
+	selector string
+	val      interface{}
+	get      func(sel *Selector, doc interface{}) (interface{}, error)
+	expected interface{}
+	err      error
+}
+
+func runGetTest(t *testing.T, fixture getTestFixture) {
+	t.Helper()
+
+	sel, err := Parse(fixture.selector)
+	if err != nil {
+		t.Errorf("could not parse selector `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	result, err := fixture.get(sel, fixture.val)
+	if diff := cmp.Diff(fixture.err, err); diff != "" {
+		t.Errorf("error for getting `%s` was not as expected:\n%s", fixture.selector, diff)
+		return
+	}
+	if diff := cmp.Diff(fixture.expected, result); diff != "" {
+		t.Errorf("`%s` was not got as expected:\n%s", fixture.selector, diff)
+	}
+}
+
+// getter adapts Get for the type to the fixture.
+func getter[T any](sel *Selector, doc interface{}) (interface{}, error) {
+	v, err := Get[T](sel, doc)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func TestGet(t *testing.T) {
+	var val map[string]interface{}
+	json.Unmarshal([]byte(`{
+		"name": "main",
+		"port": 8080,
+		"ratio": 0.5,
+		"timeout": "1m30s",
+		"created": "2020-05-01T12:00:00Z",
+		"enabled": true,
+		"ports": [80, 443],
+		"empty": null
+	}`), &val)
+
+	for _, fixture := range []getTestFixture{
+		{selector: ".name", get: getter[string], expected: "main"},
+		{selector: ".port", get: getter[int], expected: 8080},
+		{selector: ".port", get: getter[uint16], expected: uint16(8080)},
+		{selector: ".port", get: getter[float64], expected: float64(8080)},
+		{selector: ".ratio", get: getter[float32], expected: float32(0.5)},
+		{selector: ".timeout", get: getter[time.Duration], expected: 90 * time.Second},
+		{selector: ".created", get: getter[time.Time], expected: time.Date(2020, 5, 1, 12, 0, 0, 0, time.UTC)},
+		{selector: ".enabled", get: getter[bool], expected: true},
+		{selector: ".ports", get: getter[[]int], expected: []int{80, 443}},
+		{selector: ".ports[1]", get: getter[interface{}], expected: float64(443)},
+		{selector: ".empty", get: getter[[]string], expected: []string(nil)},
+		{selector: ".missing", get: getter[*int], expected: (*int)(nil)},
+		{selector: ".empty", get: getter[interface{}], expected: nil},
+		{selector: ".missing", get: getter[fmt.Stringer], expected: nil},
+	} {
+		fixture.val = val
+		runGetTest(t, fixture)
+	}
+}
+
+func TestGet_error(t *testing.T) {
+	val := map[string]interface{}{
+		"name":    "main",
+		"ratio":   0.5,
+		"big":     float64(1 << 40),
+		"timeout": "soon",
+		"created": "May 1st",
+		"ports":   []interface{}{80, "443"},
+	}
+
+	for _, fixture := range []getTestFixture{
+		{
+			selector: ".name",
+			get:      getter[int],
+			err:      ResolveError{Code: "TypeError", Msg: "cannot convert string to int", Pos: 0, Path: ".name"},
+		},
+		{
+			selector: ".ratio",
+			get:      getter[int],
+			err:      ResolveError{Code: "ValueError", Msg: "cannot convert 0.5 to int: not an integer", Pos: 0, Path: ".ratio"},
+		},
+		{
+			selector: ".big",
+			get:      getter[int32],
+			err:      ResolveError{Code: "ValueError", Msg: "cannot convert 1.099511627776e+12 to int32: out of range", Pos: 0, Path: ".big"},
+		},
+		{
+			selector: ".timeout",
+			get:      getter[time.Duration],
+			err:      ResolveError{Code: "ValueError", Msg: "cannot convert 'soon' to time.Duration: invalid duration", Pos: 0, Path: ".timeout"},
+		},
+		{
+			selector: ".created",
+			get:      getter[time.Time],
+			err:      ResolveError{Code: "ValueError", Msg: "cannot convert 'May 1st' to time.Time: not an RFC 3339 time", Pos: 0, Path: ".created"},
+		},
+		{
+			selector: ".ports",
+			get:      getter[[]int],
+			err:      ResolveError{Code: "TypeError", Msg: "element 1: cannot convert string to int", Pos: 0, Path: ".ports"},
+		},
+		{
+			selector: ".ports[0]",
+			get:      getter[string],
+			err:      ResolveError{Code: "TypeError", Msg: "cannot convert int to string", Pos: 6, Path: ".ports[0]"},
+		},
+		{
+			selector: ".missing",
+			get:      getter[int],
+			err:      ResolveError{Code: "TypeError", Msg: "cannot convert nil to int", Pos: 0, Path: ".missing"},
+		},
+	} {
+		fixture.val = val
+		runGetTest(t, fixture)
+	}
+}
+
+func TestGet_jsonNumber(t *testing.T) {
+	var val map[string]interface{}
+	d := json.NewDecoder(strings.NewReader(`{"id": 9007199254740993, "ratio": 1.5}`))
+	d.UseNumber()
+	d.Decode(&val)
+
+	runGetTest(t, getTestFixture{selector: ".id", val: val, get: getter[int64], expected: int64(9007199254740993)})
+	runGetTest(t, getTestFixture{selector: ".ratio", val: val, get: getter[float64], expected: 1.5})
+	runGetTest(t, getTestFixture{
+		selector: ".id",
+		val:      val,
+		get:      getter[string],
+		err:      ResolveError{Code: "TypeError", Msg: "cannot convert json.Number to string", Pos: 0, Path: ".id"},
+	})
+}
+
+func TestGet_yaml(t *testing.T) {
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("server:\n  port: 8080\n  timeout: 5s\n  hosts: [a, b]\n"), &doc)
+
+	runGetTest(t, getTestFixture{selector: ".server.port", val: &doc, get: getter[int], expected: 8080})
+	runGetTest(t, getTestFixture{selector: ".server.timeout", val: &doc, get: getter[time.Duration], expected: 5 * time.Second})
+	runGetTest(t, getTestFixture{selector: ".server.hosts", val: &doc, get: getter[[]string], expected: []string{"a", "b"}})
+}
+
+func TestGetHelpers(t *testing.T) {
+	val := map[string]interface{}{
+		"name":    "main",
+		"port":    float64(8080),
+		"timeout": "5s",
+		"created": "2020-05-01T12:00:00+02:00",
+	}
+
+	name, _ := Parse(".name")
+	if s, err := GetString(name, val); err != nil || s != "main" {
+		t.Errorf("expected GetString to return 'main' but got '%s' (error: %v)", s, err)
+	}
+
+	port, _ := Parse(".port")
+	if i, err := GetInt(port, val); err != nil || i != 8080 {
+		t.Errorf("expected GetInt to return 8080 but got %d (error: %v)", i, err)
+	}
+
+	timeout, _ := Parse(".timeout")
+	if d, err := GetDuration(timeout, val); err != nil || d != 5*time.Second {
+		t.Errorf("expected GetDuration to return 5s but got %s (error: %v)", d, err)
+	}
+
+	created, _ := Parse(".created")
+	expected := time.Date(2020, 5, 1, 10, 0, 0, 0, time.UTC)
+	if tm, err := GetTime(created, val); err != nil || !tm.Equal(expected) {
+		t.Errorf("expected GetTime to return %s but got %s (error: %v)", expected, tm, err)
+	}
+}
@@ -1,5 +1,16 @@
 module github.com/0xch4z/selectr
 
-go 1.14
+go 1.18
 
-require github.com/google/go-cmp v0.4.1
+require (
+	github.com/BurntSushi/toml v1.3.2
+	github.com/google/go-cmp v0.4.1
+	github.com/peterh/liner v1.2.2
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/mattn/go-runewidth v0.0.3 // indirect
+	golang.org/x/sys v0.0.0-20211117180635-dee7805ff2e1 // indirect
+	golang.org/x/xerrors v0.0.0-20191204190536-9bdfabe68543 // indirect
+)
@@ -1,6 +1,9 @@
 package ast
 
-import "strconv"
+import (
+	"strconv"
+	"strings"
+)
 
 // Expr represents an abstract expression.
 type Expr interface {
@@ -71,8 +74,53 @@ func (l *StringLit) EndPos() int {
 	return l.Node.EndPos
 }
 
+// Value returns the content of the string between its quotes with escape
+// sequences replaced by the characters they represent.
 func (l *StringLit) Value() interface{} {
-	return l.Node.Lit[1 : len(l.Node.Lit)-1]
+	return unescape(l.Node.Lit[1 : len(l.Node.Lit)-1])
+}
+
+// escapes maps the character following a backslash in an escape sequence
+// to the character it represents.
+var escapes = map[rune]rune{
+	'0':  '\x00',
+	'a':  '\a',
+	'b':  '\b',
+	'e':  '\x1b',
+	'f':  '\f',
+	'n':  '\n',
+	'r':  '\r',
+	't':  '\t',
+	'v':  '\v',
+	'\\': '\\',
+	'?':  '?',
+	'\'': '\'',
+	'"':  '"',
+}
+
+// unescape replaces the escape sequences in s with the characters they
+// represent.
+func unescape(s string) string {
+	if !strings.ContainsRune(s, '\\') {
+		return s
+	}
+
+	var b strings.Builder
+	escaped := false
+	for _, ch := range s {
+		if escaped {
+			if r, ok := escapes[ch]; ok {
+				ch = r
+			}
+			b.WriteRune(ch)
+			escaped = false
+		} else if ch == '\\' {
+			escaped = true
+		} else {
+			b.WriteRune(ch)
+		}
+	}
+	return b.String()
 }
 
 func (StringLit) expr() {}
@@ -107,3 +155,29 @@ var _ Expr = (*IntLit)(nil)
 
 // IntLit implements LitExpr
 var _ LitExpr = (*IntLit)(nil)
+
+// WildcardLit represents a wildcard, `*`, matching any key or index.
+type WildcardLit struct {
+	Node *Node
+}
+
+func (l *WildcardLit) StartPos() int {
+	return l.Node.StartPos
+}
+
+func (l *WildcardLit) EndPos() int {
+	return l.Node.EndPos
+}
+
+// Value returns nil, as a wildcard does not denote a single key or index.
+func (l *WildcardLit) Value() interface{} {
+	return nil
+}
+
+func (WildcardLit) expr() {}
+
+// WildcardLit implements Expr
+var _ Expr = (*WildcardLit)(nil)
+
+// WildcardLit implements LitExpr
+var _ LitExpr = (*WildcardLit)(nil)
@@ -3,6 +3,7 @@ package parser
 import (
 	"fmt"
 
+	"github.com/0xch4z/selectr/internal/parser/ast"
 	"github.com/0xch4z/selectr/internal/parser/token"
 )
 
@@ -53,3 +54,16 @@ func errUnexpected(pos int, lit string) *Error {
 		Msg: fmt.Sprintf("unexpected token '%s'", lit),
 	}
 }
+
+// errUnexpectedNode returns an error signaling that the node read was not
+// expected. Reaching the end of the selector is reported as such rather
+// than with the literal of the EOF token.
+func errUnexpectedNode(node *ast.Node) *Error {
+	if node.Tok == token.EOF {
+		return &Error{
+			Pos: node.StartPos,
+			Msg: "unexpected end of selector",
+		}
+	}
+	return errUnexpected(node.StartPos, node.Lit)
+}
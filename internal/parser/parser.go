@@ -72,13 +72,18 @@ func (p *Parser) parseAttributeExpr() *ast.AttrExpr {
 		// if this is the first expression, dot is optional.
 		p.unscan()
 	} else {
-		p.errs.Push(errUnexpected(node.StartPos, node.Lit))
+		p.errs.Push(errUnexpectedNode(node))
 
 		// return nil to signal an error
 		return nil
 	}
 
-	attr := p.expect(token.Ident)
+	// the attribute is either an identifier or a wildcard.
+	attr := p.scan()
+	if attr.Tok != token.Star {
+		p.unscan()
+		attr = p.expect(token.Ident)
+	}
 	return &ast.AttrExpr{
 		Dot:  dot,
 		Attr: attr,
@@ -113,9 +118,12 @@ func (p *Parser) parseLitExpr() ast.LitExpr {
 	case token.Int:
 		p.unscan()
 		return p.parseIntLit()
+
+	case token.Star:
+		return &ast.WildcardLit{Node: node}
 	}
 
-	p.errs.Push(errUnexpected(node.StartPos, node.Lit))
+	p.errs.Push(errUnexpectedNode(node))
 	return nil
 }
 
@@ -145,6 +153,17 @@ func (p *Parser) parseIndexExpression() *ast.IndexExpr {
 
 // Parse parses a selector.
 func (p *Parser) Parse() (exprs []ast.Expr, err error) {
+	if exprs, err = p.ParsePartial(); err != nil {
+		return nil, err
+	}
+	return exprs, nil
+}
+
+// ParsePartial parses a selector like Parse, but if an error occurs, the
+// expressions parsed successfully up to that point are returned alongside
+// it. This allows recovering from incomplete selectors, such as ones with a
+// trailing `.` or an unclosed `[`.
+func (p *Parser) ParsePartial() (exprs []ast.Expr, err error) {
 ParseLoop:
 	for {
 		node := p.scan()
@@ -159,7 +178,7 @@ ParseLoop:
 			// whitespace does not yield an expression; ignore it.
 			continue
 
-		case token.Dot, token.Ident:
+		case token.Dot, token.Ident, token.Star:
 			p.unscan()
 			expr = p.parseAttributeExpr()
 
@@ -170,22 +189,22 @@ ParseLoop:
 		default:
 			// attribute and index expression are the only valid top level
 			// expressions.
-			return nil, errUnexpected(node.StartPos, node.Lit)
-		}
-
-		// expr is only nil when an error has occurred that is captured
-		// on the parser or scanner.
-		if expr != nil {
-			exprs = append(exprs, expr)
+			return exprs, errUnexpected(node.StartPos, node.Lit)
 		}
 
 		// throw any underlying scanner errors, if there are any.
 		if len(p.s.errs) != 0 {
-			return nil, p.s.errs
+			return exprs, p.s.errs
 		}
 
 		if len(p.errs) != 0 {
-			return nil, p.errs
+			return exprs, p.errs
+		}
+
+		// expr is only nil when an error has occurred that is captured
+		// on the parser or scanner.
+		if expr != nil {
+			exprs = append(exprs, expr)
 		}
 	}
 
@@ -172,6 +172,66 @@ func TestParserParse_indexExpressions(t *testing.T) {
 	})
 }
 
+func TestParserParse_wildcardExpressions(t *testing.T) {
+	// attribute wildcard
+	runParserTest(t, parserFixture{
+		content: ".*",
+		expected: []ast.Expr{
+			&ast.AttrExpr{
+				Dot: &ast.Node{
+					Tok:      token.Dot,
+					Lit:      ".",
+					StartPos: 0,
+					EndPos:   1,
+				},
+				Attr: &ast.Node{
+					Tok:      token.Star,
+					Lit:      "*",
+					StartPos: 1,
+					EndPos:   2,
+				},
+			},
+		},
+	})
+
+	// index wildcard
+	runParserTest(t, parserFixture{
+		content: "[*]",
+		expected: []ast.Expr{
+			&ast.IndexExpr{
+				LBracket: &ast.Node{
+					Tok:      token.LBracket,
+					Lit:      "[",
+					StartPos: 0,
+					EndPos:   1,
+				},
+				Index: &ast.WildcardLit{
+					Node: &ast.Node{
+						Tok:      token.Star,
+						Lit:      "*",
+						StartPos: 1,
+						EndPos:   2,
+					},
+				},
+				RBracket: &ast.Node{
+					Tok:      token.RBracket,
+					Lit:      "]",
+					StartPos: 2,
+					EndPos:   3,
+				},
+			},
+		},
+	})
+
+	// a wildcard is not part of an identifier
+	runParserTest(t, parserFixture{
+		content: ".foo*",
+		err: ErrorList{
+			&Error{Pos: 4, Msg: "unexpected token '*'"},
+		},
+	})
+}
+
 func TestParserParseLitExpr_unexpectedToken(t *testing.T) {
 	parser := New(strings.NewReader("{"))
 	parser.parseLitExpr()
@@ -351,3 +411,35 @@ func TestParserParse(t *testing.T) {
 		},
 	})
 }
+
+func TestParserParse_unexpectedEOF(t *testing.T) {
+	// reaching the end of the selector in an index expression should be
+	// reported as such.
+	runParserTest(t, parserFixture{
+		content: "[",
+		err: ErrorList{&Error{
+			Pos: 1,
+			Msg: "unexpected end of selector",
+		}},
+	})
+}
+
+func TestParserParsePartial(t *testing.T) {
+	// expressions parsed before an error occurred should be returned
+	// alongside it.
+	for content, expectedLen := range map[string]int{
+		".foo.":        1,
+		".foo[":        1,
+		".foo['ba":     1,
+		".foo[0].bar[": 3,
+		"#":            0,
+	} {
+		exprs, err := New(strings.NewReader(content)).ParsePartial()
+		if err == nil {
+			t.Errorf("expected an error parsing `%s`", content)
+		}
+		if len(exprs) != expectedLen {
+			t.Errorf("expected %d expressions from parsing `%s` but got %d", expectedLen, content, len(exprs))
+		}
+	}
+}
@@ -132,7 +132,7 @@ ParseLoop:
 		case '\\':
 			escapee := s.read()
 			switch escapee {
-			case quote, 'a', 'b', 'e', 'f', 'n', 'r', 't', 'v', '\\', '?':
+			case quote, '0', 'a', 'b', 'e', 'f', 'n', 'r', 't', 'v', '\\', '?':
 				buf.WriteString("\\" + string(escapee))
 			default:
 				// the specified string escape is not recognized, so it's invalid.
@@ -190,6 +190,8 @@ func (s *Scanner) Scan() ast.Node {
 		tok = token.LBracket
 	case ']':
 		tok = token.RBracket
+	case '*':
+		tok = token.Star
 	}
 
 	return ast.Node{
@@ -14,6 +14,7 @@ const (
 	Dot
 	LBracket
 	RBracket
+	Star
 
 	// value tokens
 	String
@@ -28,6 +29,7 @@ var Tokens = [...]string{
 	Dot:      ".",
 	LBracket: "[",
 	RBracket: "]",
+	Star:     "*",
 	String:   "STRING",
 	Int:      "INT",
 }
@@ -0,0 +1,405 @@
+package selectr
+
+import (
+	"errors"
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Mutator sets and deletes values on an object.
+//
+// Both methods return the updated object, as some objects (such as
+// slices) cannot be updated in place.
+type Mutator interface {
+	Set(obj, val interface{}) (interface{}, error)
+	Delete(obj interface{}) (interface{}, error)
+}
+
+// Set sets the entry on the map.
+func (r *MapEntryResolver) Set(obj, val interface{}) (interface{}, error) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		obj[r.Key] = val
+		return obj, nil
+
+	case map[interface{}]interface{}:
+		// overwrite an existing entry with its original key so the type of
+		// the key is retained.
+		for k := range obj {
+			if interfaceKeyMatches(k, r.Key) {
+				obj[k] = val
+				return obj, nil
+			}
+		}
+		obj[r.Key] = val
+		return obj, nil
+
+	case *yaml.Node:
+		n := derefYAMLNode(obj)
+		if n != nil && n.Kind == yaml.MappingNode {
+			valNode, err := toYAMLNode(val)
+			if err != nil {
+				return nil, err
+			}
+
+			if i := yamlMappingIndex(n, yamlKeyMatcher(r.Key)); i != -1 {
+				replaceYAMLNode(n, i+1, valNode)
+			} else {
+				n.Content = append(n.Content, newYAMLStringNode(r.Key), valNode)
+			}
+			return obj, nil
+		}
+		return nil, ResolveError{
+			Code: "TypeError",
+			Msg:  fmt.Sprintf("cannot set attribute '%s' on %s", r.Key, yamlKindName(n)),
+			Pos:  r.Expr.StartPos(),
+		}
+	}
+	return nil, ResolveError{
+		Code: "TypeError",
+		Msg:  fmt.Sprintf("cannot set attribute '%s' on type %T", r.Key, obj),
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
+// Delete deletes the entry from the map. Deleting an entry that does not
+// exist is a no-op.
+func (r *MapEntryResolver) Delete(obj interface{}) (interface{}, error) {
+	switch obj := obj.(type) {
+	case map[string]interface{}:
+		delete(obj, r.Key)
+		return obj, nil
+
+	case map[interface{}]interface{}:
+		for k := range obj {
+			if interfaceKeyMatches(k, r.Key) {
+				delete(obj, k)
+			}
+		}
+		return obj, nil
+
+	case *yaml.Node:
+		n := derefYAMLNode(obj)
+		if n != nil && n.Kind == yaml.MappingNode {
+			if i := yamlMappingIndex(n, yamlKeyMatcher(r.Key)); i != -1 {
+				n.Content = append(n.Content[:i], n.Content[i+2:]...)
+			}
+			return obj, nil
+		}
+		return nil, ResolveError{
+			Code: "TypeError",
+			Msg:  fmt.Sprintf("cannot delete attribute '%s' on %s", r.Key, yamlKindName(n)),
+			Pos:  r.Expr.StartPos(),
+		}
+	}
+	return nil, ResolveError{
+		Code: "TypeError",
+		Msg:  fmt.Sprintf("cannot delete attribute '%s' on type %T", r.Key, obj),
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
+// MapEntryResolver implements Mutator.
+var _ Mutator = (*MapEntryResolver)(nil)
+
+// Set sets the element at the index on the slice. If the index is beyond
+// the end of the slice, the slice is grown and any elements in between are
+// set to nil.
+func (r *SliceElementResolver) Set(obj, val interface{}) (interface{}, error) {
+	switch obj := obj.(type) {
+	case []interface{}:
+		for len(obj) <= r.Index {
+			obj = append(obj, nil)
+		}
+		obj[r.Index] = val
+		return obj, nil
+
+	case map[interface{}]interface{}:
+		for k := range obj {
+			if n, ok := intKey(k); ok && n == int64(r.Index) {
+				obj[k] = val
+				return obj, nil
+			}
+		}
+		obj[r.Index] = val
+		return obj, nil
+
+	case *yaml.Node:
+		n := derefYAMLNode(obj)
+		if n != nil {
+			valNode, err := toYAMLNode(val)
+			if err != nil {
+				return nil, err
+			}
+
+			switch n.Kind {
+			case yaml.SequenceNode:
+				for len(n.Content) <= r.Index {
+					n.Content = append(n.Content, &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!null", Value: "null"})
+				}
+				replaceYAMLNode(n, r.Index, valNode)
+				return obj, nil
+
+			case yaml.MappingNode:
+				if i := yamlMappingIndex(n, yamlIntKeyMatcher(r.Index)); i != -1 {
+					replaceYAMLNode(n, i+1, valNode)
+				} else {
+					keyNode := &yaml.Node{Kind: yaml.ScalarNode, Tag: "!!int", Value: fmt.Sprint(r.Index)}
+					n.Content = append(n.Content, keyNode, valNode)
+				}
+				return obj, nil
+			}
+		}
+		return nil, ResolveError{
+			Code: "TypeError",
+			Msg:  fmt.Sprintf("cannot set element '%d' on %s", r.Index, yamlKindName(n)),
+			Pos:  r.Expr.StartPos(),
+		}
+	}
+	return nil, ResolveError{
+		Code: "TypeError",
+		Msg:  fmt.Sprintf("cannot set element '%d' on type %T", r.Index, obj),
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
+// Delete deletes the element at the index from the slice; subsequent
+// elements are shifted down.
+func (r *SliceElementResolver) Delete(obj interface{}) (interface{}, error) {
+	switch obj := obj.(type) {
+	case []interface{}:
+		if r.Index > len(obj)-1 {
+			return nil, r.errOutOfRange(len(obj))
+		}
+		return append(obj[:r.Index], obj[r.Index+1:]...), nil
+
+	case map[interface{}]interface{}:
+		for k := range obj {
+			if n, ok := intKey(k); ok && n == int64(r.Index) {
+				delete(obj, k)
+			}
+		}
+		return obj, nil
+
+	case *yaml.Node:
+		n := derefYAMLNode(obj)
+		if n != nil {
+			switch n.Kind {
+			case yaml.SequenceNode:
+				if r.Index > len(n.Content)-1 {
+					return nil, r.errOutOfRange(len(n.Content))
+				}
+				n.Content = append(n.Content[:r.Index], n.Content[r.Index+1:]...)
+				return obj, nil
+
+			case yaml.MappingNode:
+				if i := yamlMappingIndex(n, yamlIntKeyMatcher(r.Index)); i != -1 {
+					n.Content = append(n.Content[:i], n.Content[i+2:]...)
+				}
+				return obj, nil
+			}
+		}
+		return nil, ResolveError{
+			Code: "TypeError",
+			Msg:  fmt.Sprintf("cannot delete element '%d' on %s", r.Index, yamlKindName(n)),
+			Pos:  r.Expr.StartPos(),
+		}
+	}
+	return nil, ResolveError{
+		Code: "TypeError",
+		Msg:  fmt.Sprintf("cannot delete element '%d' on type %T", r.Index, obj),
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
+// SliceElementResolver implements Mutator.
+var _ Mutator = (*SliceElementResolver)(nil)
+
+// Set sets the value at the specified key-path on the provided object and
+// returns the updated object. Missing intermediate maps and slices are
+// created along the way. The object is modified in place where possible,
+// but the returned object must be used as slices may be reallocated.
+//
+// If the object is a yaml.v3 node tree, the value is encoded as a node and
+// the tree is edited in place, preserving comments and key order. Nodes
+// reached through an alias or a key merged in with `<<` are never modified;
+// they are copied into the node holding the alias or merge key instead.
+//
+// Example usage:
+//
+//	sel, _ := Parse(".spec.replicas")
+//	m, _ := sel.Set(map[string]interface{}{}, 3)
+//	// => map[string]interface{}{"spec": map[string]interface{}{"replicas": 3}}
+func (s *Selector) Set(v, val interface{}) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	_, isNode := v.(*yaml.Node)
+	return set(s.tree, v, val, isNode, false)
+}
+
+// SetCopy sets the value at the specified key-path like Set, but never
+// modifies the provided object. The maps, slices and yaml.v3 nodes along
+// the key-path are shallow copied before they are modified, and everything
+// else is shared between the provided and the returned object. YAML aliases
+// along the key-path are replaced by copies of the nodes they refer to.
+//
+// This makes it safe to derive documents from one shared between
+// goroutines, as long as neither is modified in place afterwards.
+func (s *Selector) SetCopy(v, val interface{}) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	_, isNode := v.(*yaml.Node)
+	return set(s.tree, v, val, isNode, true)
+}
+
+// set recursively sets the value at the traversal tree node on the object.
+// If cow is true, the object is copied before it is modified.
+func set(node *TraversalTreeNode, obj, val interface{}, isNode, cow bool) (interface{}, error) {
+	if node == nil {
+		return val, nil
+	}
+
+	m, ok := node.Resolver.(Mutator)
+	if !ok {
+		return nil, errNotMutable(node.Resolver)
+	}
+
+	if cow {
+		obj = shallowCopy(obj)
+	}
+
+	if n, ok := obj.(*yaml.Node); ok {
+		if n != nil && n.Kind == yaml.DocumentNode && len(n.Content) == 0 {
+			// values set on an empty document become its content.
+			n.Content = append(n.Content, newContainer(node.Resolver, isNode).(*yaml.Node))
+		} else if n = derefYAMLNode(n); n == nil || n.ShortTag() == "!!null" {
+			obj = nil
+		}
+	}
+	if obj == nil {
+		obj = newContainer(node.Resolver, isNode)
+	}
+
+	if node.Child == nil {
+		return m.Set(obj, val)
+	}
+
+	child, err := node.Resolver.Resolve(obj)
+	if err != nil {
+		// an element beyond the end of a slice will be created.
+		var resolveErr ResolveError
+		if !errors.As(err, &resolveErr) || resolveErr.Code != "IndexError" {
+			return nil, err
+		}
+		child = nil
+	}
+
+	// shared YAML nodes are copied rather than modified in place.
+	shared := yamlChildShared(node.Resolver, obj, child)
+	newChild, err := set(node.Child, child, val, isNode, cow || shared)
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		unanchorYAMLNode(newChild)
+	}
+	return m.Set(obj, newChild)
+}
+
+// Delete deletes the value at the specified key-path from the provided
+// object and returns the updated object. Deleting a map entry that does not
+// exist is a no-op. The object is modified in place where possible, but the
+// returned object must be used as slices may be reallocated. Like Set, YAML
+// nodes reached through an alias or merge key are copied, not modified.
+func (s *Selector) Delete(v interface{}) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.tree == nil {
+		return nil, nil
+	}
+	return del(s.tree, v, false)
+}
+
+// DeleteCopy deletes the value at the specified key-path like Delete, but
+// never modifies the provided object. The maps, slices and yaml.v3 nodes
+// along the key-path are shallow copied before they are modified, and
+// everything else is shared between the provided and the returned object.
+// If there is nothing to delete, the provided object is returned.
+func (s *Selector) DeleteCopy(v interface{}) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if s.tree == nil {
+		return nil, nil
+	}
+	return del(s.tree, v, true)
+}
+
+// del recursively deletes the value at the traversal tree node from the
+// object. If cow is true, the object is copied before it is modified.
+func del(node *TraversalTreeNode, obj interface{}, cow bool) (interface{}, error) {
+	m, ok := node.Resolver.(Mutator)
+	if !ok {
+		return nil, errNotMutable(node.Resolver)
+	}
+
+	if node.Child == nil {
+		if cow {
+			obj = shallowCopy(obj)
+		}
+		return m.Delete(obj)
+	}
+
+	child, err := node.Resolver.Resolve(obj)
+	if err != nil {
+		return nil, err
+	}
+	if child == nil {
+		// there is nothing to delete.
+		return obj, nil
+	}
+
+	shared := yamlChildShared(node.Resolver, obj, child)
+	newChild, err := del(node.Child, child, cow || shared)
+	if err != nil {
+		return nil, err
+	}
+	if shared {
+		unanchorYAMLNode(newChild)
+	}
+	if cow {
+		obj = shallowCopy(obj)
+	}
+	return m.Set(obj, newChild)
+}
+
+// errNotMutable returns an error signaling that the resolver does not
+// implement Mutator.
+func errNotMutable(r Resolver) error {
+	return ResolveError{
+		Code: "TypeError",
+		Msg:  fmt.Sprintf("cannot modify values with %T", r),
+		Pos:  r.Expression().StartPos(),
+	}
+}
+
+// newContainer returns an empty container the resolver can set values on.
+func newContainer(r Resolver, isNode bool) interface{} {
+	_, isSlice := r.(*SliceElementResolver)
+
+	if isNode {
+		if isSlice {
+			return &yaml.Node{Kind: yaml.SequenceNode, Tag: "!!seq"}
+		}
+		return &yaml.Node{Kind: yaml.MappingNode, Tag: "!!map"}
+	}
+
+	if isSlice {
+		return []interface{}{}
+	}
+	return map[string]interface{}{}
+}
@@ -0,0 +1,362 @@
+package selectr
+
+import (
+	"regexp"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type mutateTestFixture struct {
+	selector string
+	val      interface{}
+	setVal   interface{}
+	errRegex *regexp.Regexp
+	expected interface{}
+}
+
+func runSetTest(t *testing.T, fixture mutateTestFixture) {
+	t.Helper()
+
+	sel, err := Parse(fixture.selector)
+	if err != nil {
+		t.Errorf("could not parse selector `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	result, err := sel.Set(fixture.val, fixture.setVal)
+	checkMutateResult(t, fixture, result, err)
+}
+
+func runDeleteTest(t *testing.T, fixture mutateTestFixture) {
+	t.Helper()
+
+	sel, err := Parse(fixture.selector)
+	if err != nil {
+		t.Errorf("could not parse selector `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	result, err := sel.Delete(fixture.val)
+	checkMutateResult(t, fixture, result, err)
+}
+
+func checkMutateResult(t *testing.T, fixture mutateTestFixture, result interface{}, err error) {
+	t.Helper()
+
+	if fixture.errRegex != nil {
+		if err == nil {
+			t.Error("expected error to match regex but none was thrown")
+		} else if !fixture.errRegex.MatchString(err.Error()) {
+			t.Errorf("expected error to match pattern '%s' but got '%s'", fixture.errRegex, err)
+		}
+		return
+	} else if err != nil {
+		t.Errorf("unexpected error for `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	if diff := cmp.Diff(fixture.expected, result); diff != "" {
+		t.Errorf("`%s` was not modified as expected:\n%s", fixture.selector, diff)
+	}
+}
+
+func TestSet(t *testing.T) {
+	runSetTest(t, mutateTestFixture{
+		selector: ".foo",
+		val:      map[string]interface{}{"foo": 1},
+		setVal:   2,
+		expected: map[string]interface{}{"foo": 2},
+	})
+
+	// intermediate containers are created.
+	runSetTest(t, mutateTestFixture{
+		selector: ".spec.containers[1].image",
+		val:      map[string]interface{}{},
+		setVal:   "nginx",
+		expected: map[string]interface{}{
+			"spec": map[string]interface{}{
+				"containers": []interface{}{
+					nil,
+					map[string]interface{}{"image": "nginx"},
+				},
+			},
+		},
+	})
+
+	runSetTest(t, mutateTestFixture{
+		selector: "[1]",
+		val:      []interface{}{1},
+		setVal:   2,
+		expected: []interface{}{1, 2},
+	})
+
+	// keys of interface keyed maps retain their type.
+	runSetTest(t, mutateTestFixture{
+		selector: "['1']",
+		val:      map[interface{}]interface{}{1: "one"},
+		setVal:   "uno",
+		expected: map[interface{}]interface{}{1: "uno"},
+	})
+
+	// an empty selector replaces the object.
+	runSetTest(t, mutateTestFixture{
+		selector: "",
+		val:      map[string]interface{}{},
+		setVal:   1,
+		expected: 1,
+	})
+
+	runSetTest(t, mutateTestFixture{
+		selector: ".foo.bar",
+		val:      map[string]interface{}{"foo": "baz"},
+		setVal:   1,
+		errRegex: regexp.MustCompile("TypeError: cannot set attribute 'bar' on type string"),
+	})
+}
+
+func TestDelete(t *testing.T) {
+	runDeleteTest(t, mutateTestFixture{
+		selector: ".foo.bar",
+		val:      map[string]interface{}{"foo": map[string]interface{}{"bar": 1, "baz": 2}},
+		expected: map[string]interface{}{"foo": map[string]interface{}{"baz": 2}},
+	})
+
+	runDeleteTest(t, mutateTestFixture{
+		selector: ".foo[1]",
+		val:      map[string]interface{}{"foo": []interface{}{1, 2, 3}},
+		expected: map[string]interface{}{"foo": []interface{}{1, 3}},
+	})
+
+	// deleting a missing entry is a no-op.
+	runDeleteTest(t, mutateTestFixture{
+		selector: ".foo.bar",
+		val:      map[string]interface{}{"baz": 1},
+		expected: map[string]interface{}{"baz": 1},
+	})
+
+	runDeleteTest(t, mutateTestFixture{
+		selector: "[3]",
+		val:      []interface{}{1, 2, 3},
+		errRegex: regexp.MustCompile("IndexError: index out of range"),
+	})
+}
+
+func TestSet_yamlNode(t *testing.T) {
+	src := `# deployment
+spec:
+  # number of pods
+  replicas: 1 # scaled by ops
+  template: {}
+`
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	for selector, val := range map[string]interface{}{
+		".spec.replicas":               3,
+		".spec.template.labels.app":    "web",
+		".metadata.annotations['a.b']": true,
+	} {
+		sel, _ := Parse(selector)
+		if _, err := sel.Set(&doc, val); err != nil {
+			t.Fatalf("could not set `%s`: %s", selector, err)
+		}
+	}
+
+	out, err := yaml.Marshal(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# deployment
+spec:
+    # number of pods
+    replicas: 3 # scaled by ops
+    template: {labels: {app: web}}
+metadata:
+    annotations:
+        a.b: true
+`
+	if diff := cmp.Diff(expected, string(out)); diff != "" {
+		t.Errorf("YAML document was not modified as expected:\n%s", diff)
+	}
+
+	sel, _ := Parse(".spec.template")
+	if _, err := sel.Delete(&doc); err != nil {
+		t.Fatal(err)
+	}
+
+	sel, _ = Parse(".spec.template")
+	if node, _ := sel.ResolveNode(&doc); node != nil {
+		t.Errorf("expected `.spec.template` to be deleted but got %v", node)
+	}
+}
+
+func TestSet_yamlAlias(t *testing.T) {
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("base: &base\n  tls: {enabled: false}\nweb: *base\nprod:\n  <<: *base\n  port: 443\n"), &doc)
+
+	for _, selector := range []string{".web.tls.enabled", ".prod.tls.enabled"} {
+		sel, _ := Parse(selector)
+		if _, err := sel.Set(&doc, true); err != nil {
+			t.Fatalf("could not set `%s`: %s", selector, err)
+		}
+	}
+
+	// the anchored node is copied rather than modified.
+	for selector, expected := range map[string]interface{}{
+		".base.tls.enabled": false,
+		".web.tls.enabled":  true,
+		".prod.tls.enabled": true,
+		".prod.port":        443,
+	} {
+		sel, _ := Parse(selector)
+		node, _ := sel.ResolveNode(&doc)
+		var v interface{}
+		if node != nil {
+			node.Decode(&v)
+		}
+		if v != expected {
+			t.Errorf("expected `%s` to be %v but got %v", selector, expected, v)
+		}
+	}
+
+	// the copy of the mapping merged into prod is added to it once.
+	if n := len(doc.Content[0].Content[5].Content); n != 6 {
+		t.Errorf("expected prod to hold 3 entries but got %d", n/2)
+	}
+}
+
+func TestSetCopy(t *testing.T) {
+	shared := map[string]interface{}{"name": "shared"}
+	val := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"ports":    []interface{}{80, 443},
+		},
+		"shared": shared,
+	}
+	original := deepCopy(val)
+
+	for _, fixture := range []mutateTestFixture{
+		{
+			selector: ".spec.replicas",
+			val:      3,
+			expected: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": 3, "ports": []interface{}{80, 443}},
+				"shared": shared,
+			},
+		},
+		{
+			selector: ".spec.ports[2]",
+			val:      8080,
+			expected: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": 2, "ports": []interface{}{80, 443, 8080}},
+				"shared": shared,
+			},
+		},
+		{
+			selector: ".spec.ports[0]",
+			val:      8000,
+			expected: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": 2, "ports": []interface{}{8000, 443}},
+				"shared": shared,
+			},
+		},
+		{
+			selector: ".new.path",
+			val:      true,
+			expected: map[string]interface{}{
+				"spec":   map[string]interface{}{"replicas": 2, "ports": []interface{}{80, 443}},
+				"shared": shared,
+				"new":    map[string]interface{}{"path": true},
+			},
+		},
+	} {
+		sel, _ := Parse(fixture.selector)
+		result, err := sel.SetCopy(val, fixture.val)
+		if err != nil {
+			t.Errorf("could not set `%s`: %s", fixture.selector, err)
+			continue
+		}
+		if diff := cmp.Diff(fixture.expected, result); diff != "" {
+			t.Errorf("`%s` was not set as expected:\n%s", fixture.selector, diff)
+		}
+		if diff := cmp.Diff(original, val); diff != "" {
+			t.Errorf("setting `%s` modified the object:\n%s", fixture.selector, diff)
+		}
+
+		// untouched subtrees are shared.
+		if result.(map[string]interface{})["shared"].(map[string]interface{})["name"] = "changed"; shared["name"] != "changed" {
+			t.Errorf("setting `%s` copied an untouched subtree", fixture.selector)
+		}
+		shared["name"] = "shared"
+	}
+}
+
+func TestDeleteCopy(t *testing.T) {
+	val := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"replicas": 2,
+			"ports":    []interface{}{80, 443},
+		},
+	}
+	original := deepCopy(val)
+
+	for _, fixture := range []mutateTestFixture{
+		{
+			selector: ".spec.replicas",
+			expected: map[string]interface{}{"spec": map[string]interface{}{"ports": []interface{}{80, 443}}},
+		},
+		{
+			selector: ".spec.ports[0]",
+			expected: map[string]interface{}{"spec": map[string]interface{}{"replicas": 2, "ports": []interface{}{443}}},
+		},
+		{
+			selector: ".missing.key",
+			expected: original,
+		},
+	} {
+		sel, _ := Parse(fixture.selector)
+		result, err := sel.DeleteCopy(val)
+		if err != nil {
+			t.Errorf("could not delete `%s`: %s", fixture.selector, err)
+			continue
+		}
+		if diff := cmp.Diff(fixture.expected, result); diff != "" {
+			t.Errorf("`%s` was not deleted as expected:\n%s", fixture.selector, diff)
+		}
+		if diff := cmp.Diff(original, val); diff != "" {
+			t.Errorf("deleting `%s` modified the object:\n%s", fixture.selector, diff)
+		}
+	}
+}
+
+func TestSetCopy_yamlNode(t *testing.T) {
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("base: &base\n  port: 80\nweb: *base\nlist: [a, b] # items\n"), &doc)
+
+	port, _ := Parse(".web.port")
+	result, err := port.SetCopy(&doc, 8080)
+	if err != nil {
+		t.Fatalf("could not set: %s", err)
+	}
+	list, _ := Parse(".list[1]")
+	if result, err = list.DeleteCopy(result); err != nil {
+		t.Fatalf("could not delete: %s", err)
+	}
+
+	b, _ := yaml.Marshal(result)
+	expected := "base: &base\n    port: 80\nweb:\n    port: 8080\nlist: [a] # items\n"
+	if diff := cmp.Diff(expected, string(b)); diff != "" {
+		t.Errorf("node was not modified as expected:\n%s", diff)
+	}
+
+	b, _ = yaml.Marshal(&doc)
+	if diff := cmp.Diff("base: &base\n    port: 80\nweb: *base\nlist: [a, b] # items\n", string(b)); diff != "" {
+		t.Errorf("original node was modified:\n%s", diff)
+	}
+}
@@ -0,0 +1,178 @@
+package selectr
+
+import "fmt"
+
+// HandlerFunc handles the value at a path routed to it by a Mux. v is nil
+// for values that were deleted.
+type HandlerFunc func(path *Selector, v interface{}) error
+
+// Mux routes paths to the handlers of the selector patterns that match
+// them. Patterns may contain wildcards, `.*` and `[*]`, which match any
+// single key or index, e.g. `.events[*].payload.user`.
+//
+// If several patterns match a path, the most specific one is routed to:
+// the longest, and of those of the same length, the one with a key or
+// index at the first step where the others have a wildcard. E.g. for
+// `.events[0].payload`, the pattern `.events[0].payload` takes precedence
+// over `.events[0].*`, which takes precedence over `.events[*].payload`.
+//
+// Patterns are compiled into a trie of their steps, so routing a path
+// takes time proportional to its length rather than to the number of
+// patterns. The zero value is an empty Mux ready to use.
+type Mux struct {
+	root muxNode
+}
+
+// muxNode represents a step of the patterns of a Mux.
+type muxNode struct {
+	// children are the next steps selecting a key or index, keyed by
+	// their canonical segment.
+	children map[string]*muxNode
+	wildcard *muxNode
+
+	// pattern and handler are set if a pattern ends at the step.
+	pattern *Selector
+	handler HandlerFunc
+}
+
+// NewMux returns a new, empty Mux.
+func NewMux() *Mux {
+	return &Mux{}
+}
+
+// Handle registers the handler for the pattern. It panics if a handler is
+// already registered for the pattern, or if the handler is nil.
+func (m *Mux) Handle(pattern *Selector, h HandlerFunc) {
+	if h == nil {
+		panic(fmt.Sprintf("selectr: nil handler for '%s'", pattern))
+	}
+
+	n := &m.root
+	for _, segment := range pattern.segments() {
+		n = n.child(segment)
+	}
+	if n.handler != nil {
+		panic(fmt.Sprintf("selectr: multiple registrations for '%s'", pattern))
+	}
+	n.pattern, n.handler = pattern, h
+}
+
+// child returns the next step of the segment, adding it if needed.
+func (n *muxNode) child(segment string) *muxNode {
+	if segment == "[*]" {
+		if n.wildcard == nil {
+			n.wildcard = &muxNode{}
+		}
+		return n.wildcard
+	}
+
+	if c, ok := n.children[segment]; ok {
+		return c
+	}
+	if n.children == nil {
+		n.children = map[string]*muxNode{}
+	}
+	c := &muxNode{}
+	n.children[segment] = c
+	return c
+}
+
+// Match returns the most specific pattern matching the path, or the path
+// of a value containing it, and its handler. Allowing for ancestors routes
+// changes within a subtree to the handler of the subtree; e.g. the pattern
+// `.events[*].payload` matches `.events[2].payload.user.name`. nil is
+// returned if no pattern matches.
+func (m *Mux) Match(path *Selector) (pattern *Selector, h HandlerFunc) {
+	if n := m.match(path.segments(), false); n != nil {
+		return n.pattern, n.handler
+	}
+	return nil, nil
+}
+
+// Dispatch calls the handler of the most specific pattern matching the path
+// or the path of a value containing it, as returned by Match, with the
+// path and its value; e.g. after the value was set or changed. It returns
+// the error of the handler, or nil if no pattern matches.
+func (m *Mux) Dispatch(path *Selector, v interface{}) error {
+	if _, h := m.Match(path); h != nil {
+		return h(path, v)
+	}
+	return nil
+}
+
+// DispatchChanges dispatches the path and the new value of each change, in
+// order, stopping at the first error.
+func (m *Mux) DispatchChanges(changes []Change) error {
+	for _, c := range changes {
+		if err := m.Dispatch(c.Path, c.New); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Walk visits the values of the document in the order of Walk with
+// AllNodes, calling the handler of the most specific pattern matching the
+// path of each value exactly, so every handler is called with the values
+// its pattern selects. Values no pattern can match are not visited. Walk
+// stops and returns the error of a handler, unless it is SkipChildren, in
+// which case the values within the value are skipped.
+func (m *Mux) Walk(doc interface{}) error {
+	return Walk(doc, func(sel *Selector, v interface{}) error {
+		segments := sel.segments()
+		if n := m.match(segments, true); n != nil {
+			if err := n.handler(sel, v); err != nil {
+				return err
+			}
+		}
+
+		if !m.root.reaches(segments, 0) {
+			return SkipChildren
+		}
+		return nil
+	}, AllNodes())
+}
+
+// match returns the node of the most specific pattern matching the path of
+// segments, or nil. If exact is false, patterns matching the path of a
+// value containing it match as well.
+func (m *Mux) match(segments []string, exact bool) *muxNode {
+	var best *muxNode
+	bestDepth := -1
+	m.root.match(segments, 0, exact, &best, &bestDepth)
+	return best
+}
+
+// match searches the patterns of the node and the nodes after it for the
+// most specific one matching the path of segments from depth on. Steps
+// selecting keys and indices are searched before wildcards, and a pattern
+// only replaces a longer one, so that of the longest patterns, the one
+// with a key or index first where the others have a wildcard is found.
+func (n *muxNode) match(segments []string, depth int, exact bool, best **muxNode, bestDepth *int) {
+	if n.handler != nil && depth > *bestDepth && (!exact || depth == len(segments)) {
+		*best, *bestDepth = n, depth
+	}
+	if depth == len(segments) {
+		return
+	}
+
+	if c, ok := n.children[segments[depth]]; ok {
+		c.match(segments, depth+1, exact, best, bestDepth)
+	}
+	if n.wildcard != nil {
+		n.wildcard.match(segments, depth+1, exact, best, bestDepth)
+	}
+}
+
+// reaches determines if a pattern matches a path within the path of
+// segments from depth on.
+func (n *muxNode) reaches(segments []string, depth int) bool {
+	if depth == len(segments) {
+		return len(n.children) != 0 || n.wildcard != nil
+	}
+
+	if c, ok := n.children[segments[depth]]; ok && c.reaches(segments, depth+1) {
+		return true
+	}
+	return n.wildcard != nil && n.wildcard.reaches(segments, depth+1)
+}
@@ -0,0 +1,165 @@
+package selectr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+// newTestMux returns a mux with a handler for each pattern recording the
+// pattern and the path it was called with.
+func newTestMux(t *testing.T, patterns []string, calls *[]string) *Mux {
+	t.Helper()
+
+	m := NewMux()
+	for _, pattern := range patterns {
+		pattern := pattern
+		m.Handle(mustParseAll(t, pattern)[0], func(path *Selector, v interface{}) error {
+			*calls = append(*calls, pattern+" "+path.String())
+			return nil
+		})
+	}
+	return m
+}
+
+type muxMatchTestFixture struct {
+	patterns []string
+	path     string
+	expected string
+}
+
+func runMuxMatchTest(t *testing.T, fixture muxMatchTestFixture) {
+	t.Helper()
+
+	var calls []string
+	m := newTestMux(t, fixture.patterns, &calls)
+
+	pattern, h := m.Match(mustParseAll(t, fixture.path)[0])
+	if fixture.expected == "<none>" {
+		if pattern != nil || h != nil {
+			t.Errorf("expected `%s` not to match but got `%s`", fixture.path, pattern)
+		}
+		return
+	}
+	if pattern == nil || pattern.String() != fixture.expected {
+		t.Errorf("expected `%s` to match `%s` but got `%v`", fixture.path, fixture.expected, pattern)
+	}
+}
+
+func TestMuxMatch(t *testing.T) {
+	patterns := []string{
+		".events[*].payload.user",
+		".events[*].payload",
+		".events[0].*",
+		".events[0].payload",
+		".config",
+		"",
+	}
+
+	for path, expected := range map[string]string{
+		".events[3].payload.user":      ".events[*].payload.user",
+		".events[3].payload.user.name": ".events[*].payload.user",
+		".events[3].payload.meta":      ".events[*].payload",
+		".events[0].payload.meta":      ".events[0].payload",
+		".events[0].type":              ".events[0][*]",
+		".events[0].payload.user":      ".events[*].payload.user",
+		".configs":                     "",
+		".config['a.b']":               ".config",
+		"":                             "",
+	} {
+		runMuxMatchTest(t, muxMatchTestFixture{patterns: patterns, path: path, expected: expected})
+	}
+
+	runMuxMatchTest(t, muxMatchTestFixture{
+		patterns: []string{".config"},
+		path:     ".configs",
+		expected: "<none>",
+	})
+}
+
+func TestMuxHandle_duplicate(t *testing.T) {
+	m := NewMux()
+	m.Handle(mustParseAll(t, ".a")[0], func(*Selector, interface{}) error { return nil })
+
+	defer func() {
+		if r := recover(); r != "selectr: multiple registrations for '.a'" {
+			t.Errorf("unexpected panic: %v", r)
+		}
+	}()
+	m.Handle(mustParseAll(t, "['a']")[0], func(*Selector, interface{}) error { return nil })
+}
+
+func TestMuxDispatch(t *testing.T) {
+	var calls []string
+	m := newTestMux(t, []string{".events[*].payload", ".config"}, &calls)
+
+	for _, path := range []string{".events[1].payload.user", ".config", ".other"} {
+		if err := m.Dispatch(mustParseAll(t, path)[0], nil); err != nil {
+			t.Errorf("unexpected error: %s", err)
+		}
+	}
+
+	changes := Diff(
+		map[string]interface{}{"config": map[string]interface{}{"a": 1}},
+		map[string]interface{}{"config": map[string]interface{}{"a": 2}, "other": true},
+	)
+	if err := m.DispatchChanges(changes); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		".events[*].payload .events[1].payload.user",
+		".config .config",
+		".config .config.a",
+	}
+	if diff := cmp.Diff(expected, calls); diff != "" {
+		t.Errorf("unexpected handler calls:\n%s", diff)
+	}
+
+	errHandler := errors.New("handler error")
+	m.Handle(mustParseAll(t, ".fail")[0], func(*Selector, interface{}) error { return errHandler })
+	if err := m.Dispatch(mustParseAll(t, ".fail.x")[0], 1); err != errHandler {
+		t.Errorf("expected the error of the handler but got: %v", err)
+	}
+}
+
+func TestMuxWalk(t *testing.T) {
+	doc := map[string]interface{}{
+		"events": []interface{}{
+			map[string]interface{}{"payload": map[string]interface{}{"user": "ann", "id": 1}},
+			map[string]interface{}{"payload": map[string]interface{}{"user": "bob"}},
+		},
+		"config": map[string]interface{}{"debug": true},
+	}
+
+	var calls []string
+	m := newTestMux(t, []string{".events[*].payload.user", ".events[1].*", ".events[*].payload", ".config.*"}, &calls)
+	if err := m.Walk(doc); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{
+		".config.* .config.debug",
+		".events[*].payload .events[0].payload",
+		".events[*].payload.user .events[0].payload.user",
+		".events[1].* .events[1].payload",
+		".events[*].payload.user .events[1].payload.user",
+	}
+	if diff := cmp.Diff(expected, calls); diff != "" {
+		t.Errorf("unexpected handler calls:\n%s", diff)
+	}
+}
+
+func TestMuxWalk_skipChildren(t *testing.T) {
+	var calls []string
+	m := newTestMux(t, []string{".a.b"}, &calls)
+	m.Handle(mustParseAll(t, ".a")[0], func(*Selector, interface{}) error { return SkipChildren })
+
+	if err := m.Walk(map[string]interface{}{"a": map[string]interface{}{"b": 1}}); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if len(calls) != 0 {
+		t.Errorf("expected values within the value to be skipped but got: %v", calls)
+	}
+}
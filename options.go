@@ -0,0 +1,85 @@
+package selectr
+
+import "strings"
+
+// ResolveOption configures how a selector is resolved.
+type ResolveOption func(*resolveOptions)
+
+// resolveOptions holds the configuration of a resolution.
+type resolveOptions struct {
+	strict bool
+
+	// skipSuggestions leaves the suggestions out of the errors for missing
+	// keys in strict mode, for callers that only check whether keys exist.
+	skipSuggestions bool
+
+	// foldKey folds keys before comparing them when a key does not match
+	// exactly. It is nil if keys must match exactly.
+	foldKey func(string) string
+}
+
+// newResolveOptions applies the options to the default configuration.
+func newResolveOptions(opts []ResolveOption) *resolveOptions {
+	o := &resolveOptions{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// errKeyNotFound returns an error signaling that the key does not exist in
+// the container, with suggestions unless they are skipped.
+func (o *resolveOptions) errKeyNotFound(key interface{}, pos int, container interface{}) error {
+	if o.skipSuggestions {
+		return ResolveError{Code: "KeyError", Msg: keyNotFoundMsg(key, "", nil), Pos: pos}
+	}
+	return errKeyNotFound(key, pos, container)
+}
+
+// optionResolver is implemented by resolvers whose behavior can be
+// configured with options.
+type optionResolver interface {
+	resolve(v interface{}, o *resolveOptions) (interface{}, error)
+}
+
+// MapEntryResolver implements optionResolver.
+var _ optionResolver = (*MapEntryResolver)(nil)
+
+// SliceElementResolver implements optionResolver.
+var _ optionResolver = (*SliceElementResolver)(nil)
+
+// Strict makes resolving a missing map key an error rather than resolving
+// to nil. The error is a ResolveError with the code "KeyError" that lists
+// the closest existing keys as suggestions.
+func Strict() ResolveOption {
+	return func(o *resolveOptions) {
+		o.strict = true
+	}
+}
+
+// CaseInsensitive matches map keys and struct fields case-insensitively
+// when no key matches exactly. If several keys match, an AmbiguousKeyError
+// is returned.
+func CaseInsensitive() ResolveOption {
+	return func(o *resolveOptions) {
+		o.foldKey = strings.ToLower
+	}
+}
+
+// NormalizeKeys matches map keys and struct fields regardless of case and
+// word separators when no key matches exactly, so `content_type`,
+// `contentType`, `ContentType` and `content-type` are all treated as equal.
+// If several keys match, an AmbiguousKeyError is returned.
+func NormalizeKeys() ResolveOption {
+	return func(o *resolveOptions) {
+		o.foldKey = normalizeKey
+	}
+}
+
+// keySeparatorRemover removes the separators between words of a key.
+var keySeparatorRemover = strings.NewReplacer("_", "", "-", "", " ", "")
+
+// normalizeKey folds the key to lower case and removes word separators.
+func normalizeKey(k string) string {
+	return strings.ToLower(keySeparatorRemover.Replace(k))
+}
@@ -0,0 +1,390 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// PatchOp represents an operation of a JSON Patch (RFC 6902).
+type PatchOp struct {
+	Op    string      `json:"op"`
+	Path  string      `json:"path"`
+	From  string      `json:"from,omitempty"`
+	Value interface{} `json:"value,omitempty"`
+
+	// noValue signals an operation decoded without a value member, which is
+	// not the same as a null value.
+	noValue bool
+}
+
+// MarshalJSON implements json.Marshaler. The value of add, replace and
+// test operations is always written, even if it is null.
+func (op PatchOp) MarshalJSON() ([]byte, error) {
+	type patchOp PatchOp
+
+	switch op.Op {
+	case "add", "replace", "test":
+		return json.Marshal(struct {
+			Op    string      `json:"op"`
+			Path  string      `json:"path"`
+			Value interface{} `json:"value"`
+		}{op.Op, op.Path, op.Value})
+	}
+	return json.Marshal(patchOp(op))
+}
+
+// UnmarshalJSON implements json.Unmarshaler. Add, replace and test
+// operations decoded without a value fail to apply.
+func (op *PatchOp) UnmarshalJSON(b []byte) error {
+	var raw struct {
+		Op    string          `json:"op"`
+		Path  string          `json:"path"`
+		From  string          `json:"from"`
+		Value json.RawMessage `json:"value"`
+	}
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+
+	*op = PatchOp{Op: raw.Op, Path: raw.Path, From: raw.From, noValue: raw.Value == nil}
+	if raw.Value != nil {
+		return json.Unmarshal(raw.Value, &op.Value)
+	}
+	return nil
+}
+
+// JSONPatch returns the changes as the operations of a JSON Patch (RFC
+// 6902): added values are added, removed values removed and modified
+// values replaced. Applied in order, the operations of the changes returned
+// by Diff(a, b) turn a into b, up to the order of slice elements matched by
+// key.
+//
+// Example usage:
+//
+//	b, _ := json.Marshal(JSONPatch(Diff(a, b)))
+//	// => [{"op":"replace","path":"/replicas","value":3}]
+func JSONPatch(changes []Change) []PatchOp {
+	ops := make([]PatchOp, 0, len(changes))
+	for _, c := range changes {
+		path := c.Path
+		if c.patchPath != nil {
+			path = c.patchPath
+		}
+
+		op := PatchOp{Path: path.JSONPointer()}
+		switch c.Op {
+		case Added:
+			op.Op, op.Value = "add", c.New
+		case Removed:
+			op.Op = "remove"
+		default:
+			op.Op, op.Value = "replace", c.New
+		}
+		ops = append(ops, op)
+	}
+	return ops
+}
+
+// PatchError represents an error applying an operation of a patch.
+type PatchError struct {
+	// Index is the index of the operation in the patch.
+	Index int
+
+	Op  PatchOp
+	Err error
+}
+
+// Error implements (error).Error
+func (e *PatchError) Error() string {
+	return fmt.Sprintf("operation %d (%s %s): %s", e.Index, e.Op.Op, e.Op.Path, e.Err)
+}
+
+// Unwrap returns the underlying error.
+func (e *PatchError) Unwrap() error {
+	return e.Err
+}
+
+// ApplyPatch applies the operations of a JSON Patch (RFC 6902) to the
+// document and returns the patched document: add, remove, replace, move,
+// copy and test. Paths are JSON Pointers; `-` refers to the end of a slice
+// when adding. The same containers as Selector.Set are supported. Add,
+// replace and test operations decoded without a value are rejected rather
+// than treated as null.
+//
+// The patch is atomic: the operations are applied to a copy of the
+// document, which is returned only if all of them succeed. Otherwise the
+// document is returned unchanged with a *PatchError for the operation that
+// failed.
+//
+// Example usage:
+//
+//	var patch []PatchOp
+//	json.Unmarshal([]byte(`[{"op": "add", "path": "/tags/-", "value": "new"}]`), &patch)
+//	doc, err := ApplyPatch(doc, patch)
+func ApplyPatch(doc interface{}, patch []PatchOp) (interface{}, error) {
+	patched := deepCopy(doc)
+	for i, op := range patch {
+		var err error
+		if patched, err = applyPatchOp(patched, op); err != nil {
+			return doc, &PatchError{Index: i, Op: op, Err: err}
+		}
+	}
+	return patched, nil
+}
+
+// applyPatchOp applies the operation to the document.
+func applyPatchOp(doc interface{}, op PatchOp) (interface{}, error) {
+	switch op.Op {
+	case "add", "replace", "test":
+		if op.noValue {
+			return nil, fmt.Errorf("missing value")
+		}
+	}
+
+	switch op.Op {
+	case "add":
+		return patchAdd(doc, op.Path, deepCopy(op.Value))
+
+	case "remove":
+		doc, _, err := patchRemove(doc, op.Path)
+		return doc, err
+
+	case "replace":
+		sel, err := patchPath(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		return sel.Set(doc, deepCopy(op.Value))
+
+	case "move":
+		if op.Path != op.From && strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("cannot move '%s' into itself", op.From)
+		}
+		doc, v, err := patchRemove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, v)
+
+	case "copy":
+		sel, err := patchPath(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		v, err := sel.Resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+		return patchAdd(doc, op.Path, deepCopy(v))
+
+	case "test":
+		sel, err := patchPath(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		v, err := sel.Resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+		if len(Diff(v, op.Value)) != 0 {
+			return nil, fmt.Errorf("test failed: value is %s", formatValue(decodeYAMLValue(v)))
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown operation '%s'", op.Op)
+}
+
+// patchAdd adds the value at the JSON Pointer, inserting it if the parent
+// is a slice.
+func patchAdd(doc interface{}, ptr string, v interface{}) (interface{}, error) {
+	parent, parentSel, last, err := patchParent(doc, ptr)
+	if err != nil {
+		return nil, err
+	} else if parentSel == nil {
+		// the whole document is replaced.
+		return v, nil
+	}
+
+	if !isSequence(parent) {
+		sel := selectorFromKeys(append(parentSel.keys(), last))
+		return sel.Set(doc, v)
+	}
+
+	i := len(entries(parent))
+	if last != "-" {
+		if i, err = sequenceIndex(last, i+1); err != nil {
+			return nil, err
+		}
+	}
+	newParent, err := insertElement(parent, i, v)
+	if err != nil {
+		return nil, err
+	}
+	return parentSel.Set(doc, newParent)
+}
+
+// patchRemove removes the value at the JSON Pointer and returns it.
+func patchRemove(doc interface{}, ptr string) (interface{}, interface{}, error) {
+	sel, err := patchPath(doc, ptr)
+	if err != nil {
+		return nil, nil, err
+	} else if sel.tree == nil {
+		return nil, nil, fmt.Errorf("cannot remove the document")
+	}
+
+	v, err := sel.Resolve(doc)
+	if err != nil {
+		return nil, nil, err
+	}
+	doc, err = sel.Delete(doc)
+	return doc, v, err
+}
+
+// patchSelector returns the selector of the value at the reference tokens
+// of a JSON Pointer in the document, which must exist. Tokens select slice
+// indices or map keys depending on the value they are applied to.
+func patchSelector(doc interface{}, tokens []string) (*Selector, error) {
+	var keys []interface{}
+	v := doc
+	for i, token := range tokens {
+		var key interface{} = token
+		if isSequence(v) {
+			index, err := sequenceIndex(token, len(entries(v)))
+			if err != nil {
+				return nil, err
+			}
+			key = index
+		}
+		keys = append(keys, key)
+
+		var err error
+		if v, err = selectorFromKeys(keys[i:]).Resolve(v, Strict()); err != nil {
+			return nil, fmt.Errorf("path '%s' does not exist", formatPointer(tokens[:i+1]))
+		}
+	}
+	return selectorFromKeys(keys), nil
+}
+
+// patchPath returns the selector of the value at the JSON Pointer in the
+// document, which must exist.
+func patchPath(doc interface{}, ptr string) (*Selector, error) {
+	tokens, err := splitJSONPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+	return patchSelector(doc, tokens)
+}
+
+// patchParent returns the parent of the value at the JSON Pointer, its
+// selector and the last token of the pointer. The selector is nil if the
+// pointer refers to the whole document.
+func patchParent(doc interface{}, ptr string) (interface{}, *Selector, string, error) {
+	tokens, err := splitJSONPointer(ptr)
+	if err != nil || len(tokens) == 0 {
+		return nil, nil, "", err
+	}
+
+	parentSel, err := patchSelector(doc, tokens[:len(tokens)-1])
+	if err != nil {
+		return nil, nil, "", err
+	}
+	parent, err := parentSel.Resolve(doc)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if !isContainer(parent) {
+		return nil, nil, "", fmt.Errorf("cannot add to %s", formatValue(decodeYAMLValue(parent)))
+	}
+	return parent, parentSel, tokens[len(tokens)-1], nil
+}
+
+// formatPointer returns the JSON Pointer of the reference tokens.
+func formatPointer(tokens []string) string {
+	var b strings.Builder
+	for _, token := range tokens {
+		b.WriteString("/")
+		b.WriteString(pointerEscaper.Replace(token))
+	}
+	return b.String()
+}
+
+// sequenceIndex parses the token as an index of a slice of the length.
+func sequenceIndex(token string, length int) (int, error) {
+	i, ok := tokenKey(token).(int)
+	if !ok || (len(token) > 1 && token[0] == '0') {
+		return 0, fmt.Errorf("invalid index '%s'", token)
+	}
+	if i >= length {
+		return 0, fmt.Errorf("index %d out of range of slice of length %d", i, length)
+	}
+	return i, nil
+}
+
+// insertElement inserts the value at the index of the slice or yaml.v3
+// sequence node.
+func insertElement(seq interface{}, i int, v interface{}) (interface{}, error) {
+	if n, ok := seq.(*yaml.Node); ok {
+		n = derefYAMLNode(n)
+		vn, err := toYAMLNode(v)
+		if err != nil {
+			return nil, err
+		}
+		n.Content = append(n.Content[:i], append([]*yaml.Node{vn}, n.Content[i:]...)...)
+		return n, nil
+	}
+
+	s, ok := seq.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("cannot insert into %T", seq)
+	}
+	return append(s[:i:i], append([]interface{}{v}, s[i:]...)...), nil
+}
+
+// MergePatch applies a JSON Merge Patch (RFC 7396) to the document and
+// returns the patched document: maps in the patch are merged into the
+// document recursively, null values delete keys and all other values
+// replace the value at their key. The document is not modified.
+//
+// Example usage:
+//
+//	MergePatch(
+//		map[string]interface{}{"a": 1, "b": map[string]interface{}{"c": 2}},
+//		map[string]interface{}{"a": nil, "b": map[string]interface{}{"d": 3}},
+//	)
+//	// => map[string]interface{}{"b": map[string]interface{}{"c": 2, "d": 3}}
+func MergePatch(doc, patch interface{}) (interface{}, error) {
+	return mergePatch(deepCopy(doc), decodeYAMLValue(patch))
+}
+
+// mergePatch merges the patch into the document in place.
+func mergePatch(doc, patch interface{}) (interface{}, error) {
+	if !isContainer(patch) || isSequence(patch) {
+		return deepCopy(patch), nil
+	}
+	if !isContainer(doc) || isSequence(doc) {
+		doc = map[string]interface{}{}
+	}
+
+	for _, e := range entries(patch) {
+		sel := selectorFromKeys([]interface{}{fmt.Sprint(e.key)})
+
+		var err error
+		if e.val == nil {
+			doc, err = sel.Delete(doc)
+		} else {
+			var v interface{}
+			if v, err = sel.Resolve(doc); err == nil {
+				if v, err = mergePatch(v, e.val); err == nil {
+					doc, err = sel.Set(doc, v)
+				}
+			}
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
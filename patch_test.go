@@ -0,0 +1,257 @@
+package selectr
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type patchTestFixture struct {
+	doc      string
+	patch    string
+	expected string
+	err      string
+}
+
+func runPatchTest(t *testing.T, fixture patchTestFixture) {
+	t.Helper()
+
+	var doc, expected interface{}
+	var patch []PatchOp
+	json.Unmarshal([]byte(fixture.doc), &doc)
+	json.Unmarshal([]byte(fixture.expected), &expected)
+	if err := json.Unmarshal([]byte(fixture.patch), &patch); err != nil {
+		t.Fatalf("could not decode patch: %s", err)
+	}
+
+	original := deepCopy(doc)
+	result, err := ApplyPatch(doc, patch)
+
+	if fixture.err != "" {
+		if err == nil {
+			t.Errorf("expected error '%s' applying %s but got none", fixture.err, fixture.patch)
+		} else if err.Error() != fixture.err {
+			t.Errorf("expected error '%s' applying %s but got '%s'", fixture.err, fixture.patch, err)
+		}
+		expected = original
+	} else if err != nil {
+		t.Errorf("could not apply %s: %s", fixture.patch, err)
+		return
+	}
+
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("%s was not applied as expected:\n%s", fixture.patch, diff)
+	}
+	if diff := cmp.Diff(original, doc); diff != "" {
+		t.Errorf("applying %s modified the document:\n%s", fixture.patch, diff)
+	}
+}
+
+func TestApplyPatch(t *testing.T) {
+	for _, fixture := range []patchTestFixture{
+		{
+			doc:      `{"a": 1}`,
+			patch:    `[{"op": "add", "path": "/b", "value": {"c": null}}]`,
+			expected: `{"a": 1, "b": {"c": null}}`,
+		},
+		{
+			doc:      `{"a": 1}`,
+			patch:    `[{"op": "replace", "path": "/a", "value": null}, {"op": "test", "path": "/a", "value": null}]`,
+			expected: `{"a": null}`,
+		},
+		{
+			doc:      `{"list": [1, 3]}`,
+			patch:    `[{"op": "add", "path": "/list/1", "value": 2}, {"op": "add", "path": "/list/-", "value": 4}]`,
+			expected: `{"list": [1, 2, 3, 4]}`,
+		},
+		{
+			doc:      `{"a": {"0": "zero"}, "list": [1, 2, 3]}`,
+			patch:    `[{"op": "remove", "path": "/a/0"}, {"op": "remove", "path": "/list/1"}]`,
+			expected: `{"a": {}, "list": [1, 3]}`,
+		},
+		{
+			doc:      `{"a/b": {"c~d": 1}}`,
+			patch:    `[{"op": "replace", "path": "/a~1b/c~0d", "value": [true]}]`,
+			expected: `{"a/b": {"c~d": [true]}}`,
+		},
+		{
+			doc:      `{"a": {"b": 1}, "list": ["x", "y"]}`,
+			patch:    `[{"op": "move", "from": "/a/b", "path": "/list/0"}, {"op": "copy", "from": "/list", "path": "/a/copy"}]`,
+			expected: `{"a": {"copy": [1, "x", "y"]}, "list": [1, "x", "y"]}`,
+		},
+		{
+			doc:      `{"a": {"b": [1, 2]}}`,
+			patch:    `[{"op": "test", "path": "/a", "value": {"b": [1, 2]}}, {"op": "replace", "path": "", "value": "root"}]`,
+			expected: `"root"`,
+		},
+	} {
+		runPatchTest(t, fixture)
+	}
+}
+
+func TestApplyPatch_error(t *testing.T) {
+	for _, fixture := range []patchTestFixture{
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "/b", "value": 2}, {"op": "remove", "path": "/c"}]`,
+			err:   "operation 1 (remove /c): path '/c' does not exist",
+		},
+		{
+			doc:   `{"list": [1]}`,
+			patch: `[{"op": "add", "path": "/list/2", "value": 2}]`,
+			err:   "operation 0 (add /list/2): index 2 out of range of slice of length 2",
+		},
+		{
+			doc:   `{"list": [1]}`,
+			patch: `[{"op": "replace", "path": "/list/01", "value": 2}]`,
+			err:   "operation 0 (replace /list/01): invalid index '01'",
+		},
+		{
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "add", "path": "/a/b/c", "value": 2}]`,
+			err:   "operation 0 (add /a/b/c): cannot add to 1",
+		},
+		{
+			doc:   `{"a": {"b": 1}}`,
+			patch: `[{"op": "move", "from": "/a", "path": "/a/c"}]`,
+			err:   "operation 0 (move /a/c): cannot move '/a' into itself",
+		},
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "remove", "path": "/a"}, {"op": "test", "path": "", "value": {"a": 1}}]`,
+			err:   "operation 1 (test ): test failed: value is {}",
+		},
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "add", "path": "/b"}]`,
+			err:   "operation 0 (add /b): missing value",
+		},
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "replace", "path": "/a"}]`,
+			err:   "operation 0 (replace /a): missing value",
+		},
+		{
+			doc:   `{"a": null}`,
+			patch: `[{"op": "test", "path": "/a"}]`,
+			err:   "operation 0 (test /a): missing value",
+		},
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "frobnicate", "path": "/a"}]`,
+			err:   "operation 0 (frobnicate /a): unknown operation 'frobnicate'",
+		},
+		{
+			doc:   `{"a": 1}`,
+			patch: `[{"op": "remove", "path": "a"}]`,
+			err:   "operation 0 (remove a): JSON Pointer must begin with /",
+		},
+	} {
+		runPatchTest(t, fixture)
+	}
+
+	var patchErr *PatchError
+	_, err := ApplyPatch(map[string]interface{}{}, []PatchOp{{Op: "remove", Path: "/a"}})
+	if !errors.As(err, &patchErr) || patchErr.Index != 0 || patchErr.Op.Path != "/a" {
+		t.Errorf("expected a *PatchError for the failing operation but got %v", err)
+	}
+}
+
+func TestApplyPatch_yaml(t *testing.T) {
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("# comment\nlist: [a, c]\nname: web # the name\n"), &doc)
+
+	result, err := ApplyPatch(&doc, []PatchOp{
+		{Op: "add", Path: "/list/1", Value: "b"},
+		{Op: "replace", Path: "/name", Value: "api"},
+	})
+	if err != nil {
+		t.Fatalf("could not apply patch: %s", err)
+	}
+
+	b, _ := yaml.Marshal(result)
+	expected := "# comment\nlist: [a, b, c]\nname: api # the name\n"
+	if diff := cmp.Diff(expected, string(b)); diff != "" {
+		t.Errorf("patch was not applied as expected:\n%s", diff)
+	}
+
+	b, _ = yaml.Marshal(&doc)
+	if diff := cmp.Diff("# comment\nlist: [a, c]\nname: web # the name\n", string(b)); diff != "" {
+		t.Errorf("applying the patch modified the document:\n%s", diff)
+	}
+}
+
+func TestApplyPatch_diff(t *testing.T) {
+	id, _ := Parse(".id")
+
+	var a, b interface{}
+	json.Unmarshal([]byte(`{"items": [{"id": 1, "v": "a"}, {"id": 2, "v": "b"}, {"id": 3}], "x": 1}`), &a)
+	json.Unmarshal([]byte(`{"items": [{"id": 0}, {"id": 1, "v": "A"}, {"id": 3}, {"id": 4}], "y": 2}`), &b)
+
+	// the JSON Patch of a diff turns a into b.
+	result, err := ApplyPatch(a, JSONPatch(Diff(a, b, MatchElementsBy(id))))
+	if err != nil {
+		t.Fatalf("could not apply patch: %s", err)
+	}
+	if diff := cmp.Diff(b, result); diff != "" {
+		t.Errorf("patch was not applied as expected:\n%s", diff)
+	}
+
+	// matched elements keep their order in a, so the patch of reordered
+	// elements turns a into b up to their order.
+	json.Unmarshal([]byte(`{"items": [{"id": 1, "v": 1}, {"id": 2, "v": 2}, {"id": 3, "v": 3}]}`), &a)
+	json.Unmarshal([]byte(`{"items": [{"id": 4}, {"id": 3, "v": 3}, {"id": 1, "v": 9}]}`), &b)
+
+	result, err = ApplyPatch(a, JSONPatch(Diff(a, b, MatchElementsBy(id))))
+	if err != nil {
+		t.Fatalf("could not apply patch: %s", err)
+	}
+	var expected interface{}
+	json.Unmarshal([]byte(`{"items": [{"id": 4}, {"id": 1, "v": 9}, {"id": 3, "v": 3}]}`), &expected)
+	if diff := cmp.Diff(expected, result); diff != "" {
+		t.Errorf("patch of reordered elements was not applied as expected:\n%s", diff)
+	}
+	if changes := Diff(result, b, MatchElementsBy(id)); len(changes) != 0 {
+		t.Errorf("expected the patched document to match b but got changes:\n%s", FormatChanges(changes))
+	}
+}
+
+func TestMergePatch(t *testing.T) {
+	for _, fixture := range []patchTestFixture{
+		{doc: `{"a": "b"}`, patch: `{"a": "c"}`, expected: `{"a": "c"}`},
+		{doc: `{"a": "b"}`, patch: `{"b": "c"}`, expected: `{"a": "b", "b": "c"}`},
+		{doc: `{"a": "b"}`, patch: `{"a": null}`, expected: `{}`},
+		{doc: `{"a": "b", "b": "c"}`, patch: `{"a": null}`, expected: `{"b": "c"}`},
+		{doc: `{"a": ["b"]}`, patch: `{"a": "c"}`, expected: `{"a": "c"}`},
+		{doc: `{"a": "c"}`, patch: `{"a": ["b"]}`, expected: `{"a": ["b"]}`},
+		{doc: `{"a": {"b": "c"}}`, patch: `{"a": {"b": "d", "c": null}}`, expected: `{"a": {"b": "d"}}`},
+		{doc: `{"a": [{"b": "c"}]}`, patch: `{"a": [1]}`, expected: `{"a": [1]}`},
+		{doc: `["a", "b"]`, patch: `["c", "d"]`, expected: `["c", "d"]`},
+		{doc: `{"a": "b"}`, patch: `["c"]`, expected: `["c"]`},
+		{doc: `{"a": "foo"}`, patch: `null`, expected: `null`},
+		{doc: `{"e": null}`, patch: `{"a": 1}`, expected: `{"e": null, "a": 1}`},
+		{doc: `[1, 2]`, patch: `{"a": "b", "c": null}`, expected: `{"a": "b"}`},
+		{doc: `{}`, patch: `{"a": {"bb": {"ccc": null}}}`, expected: `{"a": {"bb": {}}}`},
+	} {
+		var doc, patch, expected interface{}
+		json.Unmarshal([]byte(fixture.doc), &doc)
+		json.Unmarshal([]byte(fixture.patch), &patch)
+		json.Unmarshal([]byte(fixture.expected), &expected)
+
+		original := deepCopy(doc)
+		result, err := MergePatch(doc, patch)
+		if err != nil {
+			t.Errorf("could not merge %s into %s: %s", fixture.patch, fixture.doc, err)
+			continue
+		}
+		if diff := cmp.Diff(expected, result); diff != "" {
+			t.Errorf("%s was not merged into %s as expected:\n%s", fixture.patch, fixture.doc, diff)
+		}
+		if diff := cmp.Diff(original, doc); diff != "" {
+			t.Errorf("merging %s modified the document:\n%s", fixture.patch, diff)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package selectr
+
+import "fmt"
+
+// StepKind is the kind of a step of a selector.
+type StepKind int
+
+const (
+	// KeyStep selects an entry of a map or a field of a struct.
+	KeyStep StepKind = iota
+
+	// IndexStep selects an element of a slice.
+	IndexStep
+
+	// WildcardStep selects every entry of a map and every element of a
+	// slice.
+	WildcardStep
+)
+
+// String returns the name of the step kind.
+func (k StepKind) String() string {
+	switch k {
+	case KeyStep:
+		return "key"
+	case IndexStep:
+		return "index"
+	case WildcardStep:
+		return "wildcard"
+	}
+	return fmt.Sprintf("StepKind(%d)", int(k))
+}
+
+// Kind returns the kind of the step the node resolves.
+func (n *TraversalTreeNode) Kind() StepKind {
+	switch n.Resolver.(type) {
+	case *SliceElementResolver:
+		return IndexStep
+	case *WildcardResolver:
+		return WildcardStep
+	}
+	return KeyStep
+}
+
+// Value returns the key (a string) or the index (an int) the node selects,
+// or nil for a wildcard.
+func (n *TraversalTreeNode) Value() interface{} {
+	key := stepKey(n.Resolver)
+	if _, ok := key.(wildcard); ok {
+		return nil
+	}
+	return key
+}
+
+// Len returns the number of steps of the selector.
+func (s *Selector) Len() int {
+	n := 0
+	for curr := s.tree; curr != nil; curr = curr.Child {
+		n++
+	}
+	return n
+}
+
+// Steps returns the nodes of the traversal tree of the selector, one per
+// step, in order. The nodes are shared with the selector and must not be
+// modified.
+func (s *Selector) Steps() []*TraversalTreeNode {
+	var steps []*TraversalTreeNode
+	for curr := s.tree; curr != nil; curr = curr.Child {
+		steps = append(steps, curr)
+	}
+	return steps
+}
+
+// Last returns the node of the last step of the selector, or nil if the
+// selector is empty.
+func (s *Selector) Last() *TraversalTreeNode {
+	curr := s.tree
+	for curr != nil && curr.Child != nil {
+		curr = curr.Child
+	}
+	return curr
+}
+
+// Parent returns the selector of the value containing the value the
+// selector selects, i.e. the selector without its last step. The parent of
+// the empty selector is the empty selector.
+func (s *Selector) Parent() *Selector {
+	keys := s.keys()
+	if len(keys) == 0 {
+		return &Selector{}
+	}
+	return selectorFromKeys(keys[:len(keys)-1])
+}
+
+// Equal determines if the selectors select the same path. Selectors are
+// compared by their steps, so `.a` equals `['a']`.
+func (s *Selector) Equal(other *Selector) bool {
+	a, b := s.segments(), other.segments()
+	return len(a) == len(b) && hasSegmentPrefix(a, b)
+}
+
+// HasPrefix determines if the selector begins with the steps of the
+// prefix, i.e. if it selects the value the prefix selects or a value within
+// it. Steps are compared as a whole, so `.username` does not begin with
+// `.user`, and wildcards only match wildcards.
+func (s *Selector) HasPrefix(prefix *Selector) bool {
+	return hasSegmentPrefix(s.segments(), prefix.segments())
+}
+
+// Rel returns the selector that, joined to base, is the selector, e.g.
+// `.name` for `.user.name` relative to `.user`. An error is returned if the
+// selector does not begin with base.
+func (s *Selector) Rel(base *Selector) (*Selector, error) {
+	if !s.HasPrefix(base) {
+		return nil, fmt.Errorf("'%s' is not within '%s'", s, base)
+	}
+	return selectorFromKeys(s.keys()[base.Len():]), nil
+}
+
+// Join returns the selector of the steps of the selectors in order, e.g.
+// `.user.name` for `.user` and `.name`.
+func Join(selectors ...*Selector) *Selector {
+	var keys []interface{}
+	for _, sel := range selectors {
+		keys = append(keys, sel.keys()...)
+	}
+	return selectorFromKeys(keys)
+}
@@ -0,0 +1,133 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestSelectorSteps(t *testing.T) {
+	sel := mustParseAll(t, ".users[1]['content-type'][*]")[0]
+
+	type step struct {
+		Kind  StepKind
+		Value interface{}
+	}
+	var steps []step
+	for _, n := range sel.Steps() {
+		steps = append(steps, step{Kind: n.Kind(), Value: n.Value()})
+	}
+
+	expected := []step{
+		{Kind: KeyStep, Value: "users"},
+		{Kind: IndexStep, Value: 1},
+		{Kind: KeyStep, Value: "content-type"},
+		{Kind: WildcardStep, Value: nil},
+	}
+	if diff := cmp.Diff(expected, steps); diff != "" {
+		t.Errorf("unexpected steps:\n%s", diff)
+	}
+
+	if n := sel.Len(); n != 4 {
+		t.Errorf("expected 4 steps but got %d", n)
+	}
+	if last := sel.Last(); last.Kind() != WildcardStep {
+		t.Errorf("unexpected last step: %s", last.Kind())
+	}
+
+	empty := mustParseAll(t, "")[0]
+	if empty.Len() != 0 || empty.Last() != nil || empty.Steps() != nil {
+		t.Errorf("expected the empty selector to have no steps")
+	}
+}
+
+type selectorPathTestFixture struct {
+	selector string
+	other    string
+	expected interface{}
+}
+
+func TestSelectorParent(t *testing.T) {
+	for _, fixture := range []selectorPathTestFixture{
+		{selector: ".user.name", expected: ".user"},
+		{selector: ".users[0]", expected: ".users"},
+		{selector: ".user", expected: ""},
+		{selector: "", expected: ""},
+	} {
+		parent := mustParseAll(t, fixture.selector)[0].Parent()
+		if parent.String() != fixture.expected {
+			t.Errorf("expected the parent of `%s` to be `%s` but got `%s`", fixture.selector, fixture.expected, parent)
+		}
+	}
+}
+
+func TestSelectorHasPrefix(t *testing.T) {
+	for _, fixture := range []selectorPathTestFixture{
+		{selector: ".user.name", other: ".user", expected: true},
+		{selector: ".user.name", other: "['user']", expected: true},
+		{selector: ".user", other: ".user", expected: true},
+		{selector: ".user", other: "", expected: true},
+		{selector: ".username", other: ".user", expected: false},
+		{selector: ".user", other: ".user.name", expected: false},
+		{selector: ".users[10]", other: ".users[1]", expected: false},
+		{selector: ".users[0]", other: ".users[*]", expected: false},
+	} {
+		sels := mustParseAll(t, fixture.selector, fixture.other)
+		if hasPrefix := sels[0].HasPrefix(sels[1]); hasPrefix != fixture.expected {
+			t.Errorf("expected `%s` to have prefix `%s` %t but got %t", fixture.selector, fixture.other, fixture.expected, hasPrefix)
+		}
+	}
+}
+
+func TestSelectorEqual(t *testing.T) {
+	for _, fixture := range []selectorPathTestFixture{
+		{selector: ".user.name", other: "user['name']", expected: true},
+		{selector: "", other: "", expected: true},
+		{selector: ".user", other: ".user.name", expected: false},
+		{selector: ".a[0]", other: ".a['0']", expected: false},
+	} {
+		sels := mustParseAll(t, fixture.selector, fixture.other)
+		if equal := sels[0].Equal(sels[1]); equal != fixture.expected {
+			t.Errorf("expected `%s` to equal `%s` %t but got %t", fixture.selector, fixture.other, fixture.expected, equal)
+		}
+	}
+}
+
+func TestSelectorRel(t *testing.T) {
+	for _, fixture := range []selectorPathTestFixture{
+		{selector: ".user.name", other: ".user", expected: ".name"},
+		{selector: ".users[0]['content-type']", other: ".users", expected: "[0]['content-type']"},
+		{selector: ".user", other: ".user", expected: ""},
+	} {
+		sels := mustParseAll(t, fixture.selector, fixture.other)
+		rel, err := sels[0].Rel(sels[1])
+		if err != nil {
+			t.Errorf("unexpected error: %s", err)
+		} else if rel.String() != fixture.expected {
+			t.Errorf("expected `%s` relative to `%s` to be `%s` but got `%s`", fixture.selector, fixture.other, fixture.expected, rel)
+		}
+	}
+
+	sels := mustParseAll(t, ".username", ".user")
+	if _, err := sels[0].Rel(sels[1]); err == nil || err.Error() != "'.username' is not within '.user'" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestJoin(t *testing.T) {
+	sels := mustParseAll(t, ".users", "[0]", "", "['content-type']")
+	if joined := Join(sels...).String(); joined != ".users[0]['content-type']" {
+		t.Errorf("unexpected joined selector: %s", joined)
+	}
+	if joined := Join().String(); joined != "" {
+		t.Errorf("unexpected joined selector: %s", joined)
+	}
+
+	// the joined selector resolves like the selectors in turn.
+	v, err := Join(sels...).Resolve(map[string]interface{}{
+		"users": []interface{}{map[string]interface{}{"content-type": "json"}},
+	})
+	if err != nil || v != "json" {
+		t.Errorf("unexpected value: %v (%v)", v, err)
+	}
+}
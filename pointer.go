@@ -0,0 +1,85 @@
+package selectr
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/0xch4z/selectr/internal/parser"
+)
+
+// pointerEscaper escapes the characters with a special meaning in the
+// reference tokens of a JSON Pointer.
+var pointerEscaper = strings.NewReplacer("~", "~0", "/", "~1")
+
+// pointerUnescaper unescapes the reference tokens of a JSON Pointer.
+var pointerUnescaper = strings.NewReplacer("~1", "/", "~0", "~")
+
+// JSONPointer returns the selector as a JSON Pointer (RFC 6901), e.g.
+// `/accounts/0/name` for `.accounts[0].name`. The empty selector is the
+// empty pointer.
+func (s *Selector) JSONPointer() string {
+	var tokens []string
+	for _, key := range s.keys() {
+		tokens = append(tokens, fmt.Sprint(key))
+	}
+	return formatPointer(tokens)
+}
+
+// ParseJSONPointer parses a JSON Pointer (RFC 6901) into a selector.
+// Reference tokens consisting of digits select slice indices; all other
+// tokens select map keys.
+//
+// Example usage:
+//
+//	sel, _ := ParseJSONPointer("/accounts/0/content~1type")
+//	sel.String() // => ".accounts[0]['content/type']"
+func ParseJSONPointer(ptr string) (*Selector, error) {
+	tokens, err := splitJSONPointer(ptr)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make([]interface{}, 0, len(tokens))
+	for _, token := range tokens {
+		keys = append(keys, tokenKey(token))
+	}
+	return selectorFromKeys(keys), nil
+}
+
+// splitJSONPointer returns the unescaped reference tokens of a JSON
+// Pointer.
+func splitJSONPointer(ptr string) ([]string, error) {
+	if ptr == "" {
+		return nil, nil
+	}
+	if ptr[0] != '/' {
+		return nil, &parser.Error{Pos: 0, Msg: "JSON Pointer must begin with /"}
+	}
+
+	var tokens []string
+	pos := 1
+	for _, token := range strings.Split(ptr[1:], "/") {
+		runes := []rune(token)
+		for i, ch := range runes {
+			if ch == '~' && (i == len(runes)-1 || (runes[i+1] != '0' && runes[i+1] != '1')) {
+				return nil, &parser.Error{Pos: pos + i, Msg: "invalid escape sequence in JSON Pointer"}
+			}
+		}
+
+		tokens = append(tokens, pointerUnescaper.Replace(token))
+		pos += len(runes) + 1
+	}
+	return tokens, nil
+}
+
+// tokenKey returns the key a path token selects: a slice index if it
+// consists of digits, or a map key otherwise.
+func tokenKey(token string) interface{} {
+	if token != "" && strings.Trim(token, "0123456789") == "" {
+		if i, err := strconv.Atoi(token); err == nil {
+			return i
+		}
+	}
+	return token
+}
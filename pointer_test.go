@@ -0,0 +1,45 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/0xch4z/selectr/internal/parser"
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseJSONPointer(t *testing.T) {
+	for ptr, expected := range map[string]string{
+		"":                 "",
+		"/":                "['']",
+		"/accounts/0/name": ".accounts[0].name",
+		"/a~1b/c~0d/~01":   "['a/b']['c~d']['~1']",
+		"/content-type/10": "['content-type'][10]",
+		"/unicode/été":     ".unicode['été']",
+		"/a\x00b":          `['a\0b']`,
+	} {
+		sel, err := ParseJSONPointer(ptr)
+		if err != nil {
+			t.Errorf("could not parse JSON Pointer '%s': %s", ptr, err)
+			continue
+		}
+		if s := sel.String(); s != expected {
+			t.Errorf("expected '%s' to be parsed as `%s` but got `%s`", ptr, expected, s)
+		}
+		if p := sel.JSONPointer(); p != ptr {
+			t.Errorf("expected `%s` to be formatted as '%s' but got '%s'", expected, ptr, p)
+		}
+	}
+}
+
+func TestParseJSONPointer_error(t *testing.T) {
+	for ptr, expected := range map[string]error{
+		"a/b":    &parser.Error{Pos: 0, Msg: "JSON Pointer must begin with /"},
+		"/ab/c~": &parser.Error{Pos: 5, Msg: "invalid escape sequence in JSON Pointer"},
+		"/é~2":   &parser.Error{Pos: 2, Msg: "invalid escape sequence in JSON Pointer"},
+	} {
+		_, err := ParseJSONPointer(ptr)
+		if diff := cmp.Diff(expected, err); diff != "" {
+			t.Errorf("error for parsing '%s' was not as expected:\n%s", ptr, diff)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package selectr
+
+// Policy controls access to the fields of documents with allow and deny
+// selector patterns. Patterns may contain wildcards, `.*` and `[*]`, which
+// match any single key or index.
+//
+// A pattern applies to the path it matches and to everything within it:
+// allowing `.user` allows `.user.name`, but not `.username`. Paths are
+// compared key by key, never as strings. If several patterns apply to a
+// path, the most specific one, i.e. the longest, decides; a deny pattern
+// wins over an allow pattern of the same length. Paths no pattern applies
+// to are allowed only if there are no allow patterns.
+//
+// Example usage:
+//
+//	user, _ := Parse(".user")
+//	password, _ := Parse(".user.password")
+//	p := &Policy{Allow: []*Selector{user}, Deny: []*Selector{password}}
+//
+//	name, _ := Parse(".user.name")
+//	p.Allowed(name)     // => true
+//	p.Allowed(password) // => false
+type Policy struct {
+	Allow []*Selector
+	Deny  []*Selector
+}
+
+// Allowed determines if the policy allows access to the path.
+func (p *Policy) Allowed(path *Selector) bool {
+	return p.allowed(path.segments())
+}
+
+// allowed determines if the policy allows access to the path of segments.
+func (p *Policy) allowed(segments []string) bool {
+	allowed, best := len(p.Allow) == 0, -1
+	for _, pattern := range p.Allow {
+		if n := len(pattern.segments()); n > best && matchesPattern(segments, pattern.segments()) {
+			allowed, best = true, n
+		}
+	}
+	for _, pattern := range p.Deny {
+		if n := len(pattern.segments()); n >= best && matchesPattern(segments, pattern.segments()) {
+			allowed, best = false, n
+		}
+	}
+	return allowed
+}
+
+// Apply returns a copy of the document with the values the policy denies
+// access to removed. Maps and slices containing allowed values are kept,
+// with only the allowed values, even if they are denied themselves; denied
+// elements are removed from slices, shifting the elements after them. nil
+// is returned if nothing is allowed.
+//
+// The document is never modified; the maps and slices along the removed
+// paths are copied as with DeleteCopy. yaml.v3 nodes are decoded, and
+// documents are built of maps and slices.
+func (p *Policy) Apply(doc interface{}) (interface{}, error) {
+	doc = decodeYAMLValue(doc)
+
+	denied, keep := p.prune(doc, nil)
+	if !keep {
+		return nil, nil
+	}
+
+	// paths are collected in order, so deleting them in reverse removes
+	// elements from the end of slices first, keeping the indices of the
+	// remaining paths valid.
+	var err error
+	for i := len(denied) - 1; i >= 0; i-- {
+		if doc, err = selectorFromKeys(denied[i]).DeleteCopy(doc); err != nil {
+			return nil, err
+		}
+	}
+	return doc, nil
+}
+
+// prune returns the paths of the values within the value at the path of
+// keys the policy denies access to, and whether any of the value is
+// allowed. Paths within a denied path are not returned.
+func (p *Policy) prune(v interface{}, keys []interface{}) (denied [][]interface{}, keep bool) {
+	segments := keySegments(keys)
+	allowed := p.allowed(segments)
+	if !isContainer(v) || !p.hasPatternWithin(segments) {
+		return nil, allowed
+	}
+
+	keep = allowed
+	for _, e := range entries(v) {
+		path := appendKey(keys, e.key)
+		childDenied, childKeep := p.prune(e.val, path)
+		if !childKeep {
+			denied = append(denied, path)
+			continue
+		}
+		denied = append(denied, childDenied...)
+		keep = true
+	}
+	return denied, keep
+}
+
+// hasPatternWithin determines if a pattern matches a path within the path
+// of segments.
+func (p *Policy) hasPatternWithin(segments []string) bool {
+	for _, patterns := range [][]*Selector{p.Allow, p.Deny} {
+		for _, pattern := range patterns {
+			ps := pattern.segments()
+			if len(ps) > len(segments) && matchesPattern(segments, ps[:len(segments)]) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// matchesPattern determines if the path of segments is matched by the
+// pattern segments or is within a path matched by them.
+func matchesPattern(segments, pattern []string) bool {
+	if len(pattern) > len(segments) {
+		return false
+	}
+	for i, ps := range pattern {
+		if ps != "[*]" && ps != segments[i] {
+			return false
+		}
+	}
+	return true
+}
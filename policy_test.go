@@ -0,0 +1,199 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type policyAllowedTestFixture struct {
+	allow    []string
+	deny     []string
+	path     string
+	expected bool
+}
+
+func runPolicyAllowedTest(t *testing.T, fixture policyAllowedTestFixture) {
+	t.Helper()
+
+	p := &Policy{Allow: mustParseAll(t, fixture.allow...), Deny: mustParseAll(t, fixture.deny...)}
+	path := mustParseAll(t, fixture.path)[0]
+	if allowed := p.Allowed(path); allowed != fixture.expected {
+		t.Errorf("expected `%s` to be allowed %t with allow %v and deny %v, but got %t",
+			fixture.path, fixture.expected, fixture.allow, fixture.deny, allowed)
+	}
+}
+
+func TestPolicyAllowed(t *testing.T) {
+	// everything is allowed without patterns.
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		path:     ".user.name",
+		expected: true,
+	})
+
+	// allowing a path allows everything within it.
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user"},
+		path:     ".user.name",
+		expected: true,
+	})
+
+	// paths are compared by key, not as strings.
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user"},
+		path:     ".username",
+		expected: false,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user.name"},
+		path:     ".user",
+		expected: false,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{"['user']"},
+		path:     ".user.name",
+		expected: true,
+	})
+
+	// the most specific pattern decides.
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user"},
+		deny:     []string{".user.password"},
+		path:     ".user.password",
+		expected: false,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user.name"},
+		deny:     []string{".user"},
+		path:     ".user.name",
+		expected: true,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".user"},
+		deny:     []string{".user"},
+		path:     ".user.name",
+		expected: false,
+	})
+
+	// wildcards match any key or index.
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		deny:     []string{".users[*].password"},
+		path:     ".users[3].password",
+		expected: false,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		deny:     []string{".users[*].password"},
+		path:     ".users[3].name",
+		expected: true,
+	})
+
+	runPolicyAllowedTest(t, policyAllowedTestFixture{
+		allow:    []string{".*.name"},
+		path:     ".owner.name.first",
+		expected: true,
+	})
+}
+
+type policyApplyTestFixture struct {
+	allow    []string
+	deny     []string
+	val      interface{}
+	expected interface{}
+}
+
+func runPolicyApplyTest(t *testing.T, fixture policyApplyTestFixture) {
+	t.Helper()
+
+	p := &Policy{Allow: mustParseAll(t, fixture.allow...), Deny: mustParseAll(t, fixture.deny...)}
+	applied, err := p.Apply(fixture.val)
+	if err != nil {
+		t.Errorf("unexpected error applying allow %v and deny %v: %s", fixture.allow, fixture.deny, err)
+		return
+	}
+	if diff := cmp.Diff(fixture.expected, applied); diff != "" {
+		t.Errorf("allow %v and deny %v were not applied as expected:\n%s", fixture.allow, fixture.deny, diff)
+	}
+}
+
+func TestPolicyApply(t *testing.T) {
+	doc := newUsersDoc()
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		deny: []string{".users[*].email", ".owner"},
+		val:  doc,
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "ann", "tags": []interface{}{"admin"}},
+				map[string]interface{}{"name": "bob"},
+				"invalid",
+			},
+			"count": 2,
+		},
+	})
+
+	// the document is not modified.
+	if diff := cmp.Diff(newUsersDoc(), doc); diff != "" {
+		t.Errorf("document was modified:\n%s", diff)
+	}
+
+	// containers of allowed values are kept.
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		allow: []string{".users[*].name", ".count"},
+		val:   newUsersDoc(),
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "ann"},
+				map[string]interface{}{"name": "bob"},
+			},
+			"count": 2,
+		},
+	})
+
+	// denied elements are removed from slices.
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		deny: []string{".users[0]", ".users[2]", ".users[1].email"},
+		val:  newUsersDoc(),
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "bob"},
+			},
+			"owner": map[string]interface{}{"name": "ann", "email": "ann@example.com"},
+			"count": 2,
+		},
+	})
+
+	// allowed containers are kept, even if everything within is denied.
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		allow:    []string{".owner"},
+		deny:     []string{".owner.*"},
+		val:      newUsersDoc(),
+		expected: map[string]interface{}{"owner": map[string]interface{}{}},
+	})
+
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		allow:    []string{".missing"},
+		val:      newUsersDoc(),
+		expected: nil,
+	})
+}
+
+func TestPolicyApply_yaml(t *testing.T) {
+	var doc yaml.Node
+	src := "defaults: &defaults\n  password: s3cr3t\nuser:\n  <<: *defaults\n  name: ann\n"
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	// merged keys are removed as well.
+	runPolicyApplyTest(t, policyApplyTestFixture{
+		allow:    []string{".user"},
+		deny:     []string{".*.password"},
+		val:      &doc,
+		expected: map[string]interface{}{"user": map[string]interface{}{"name": "ann"}},
+	})
+}
@@ -0,0 +1,155 @@
+package selectr
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+)
+
+// Project returns a new document containing only the values the selectors
+// match on the document, including those matched by wildcards, with their
+// ancestors kept in shape: keys stay under the same maps and elements at
+// the same indices, with nil in place of the elements in between. Paths
+// that do not exist on the document are ignored.
+//
+// The values are deep copied, so the document is never modified. yaml.v3
+// nodes are decoded, and the projection is built of maps and slices.
+//
+// Example usage:
+//
+//	name, _ := Parse(".accounts[*].name")
+//	Project(map[string]interface{}{
+//		"accounts": []interface{}{
+//			map[string]interface{}{"name": "main", "token": "s3cr3t"},
+//		},
+//	}, name)
+//	// => map[string]interface{}{"accounts": []interface{}{
+//	//	map[string]interface{}{"name": "main"},
+//	// }}
+func Project(doc interface{}, selectors ...*Selector) (interface{}, error) {
+	var projection interface{}
+	for _, sel := range selectors {
+		matches, err := sel.Expand(doc)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, match := range matches {
+			v, err := match.Resolve(doc)
+			if err != nil {
+				return nil, err
+			}
+
+			v = deepCopy(decodeYAMLValue(v))
+			if match.tree == nil {
+				// the whole document is selected.
+				projection = v
+				continue
+			}
+			if projection, err = match.Set(projection, v); err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if projection == nil {
+		// nothing was selected; keep the shape of the document.
+		switch {
+		case isSequence(decodeYAMLValue(doc)):
+			projection = []interface{}{}
+		case isContainer(doc):
+			projection = map[string]interface{}{}
+		}
+	}
+	return projection, nil
+}
+
+// Mask returns the value a redacted value is replaced with.
+type Mask func(v interface{}) interface{}
+
+// MaskWith masks every value with the replacement, e.g. "[REDACTED]".
+func MaskWith(replacement interface{}) Mask {
+	return func(interface{}) interface{} {
+		return replacement
+	}
+}
+
+// HashMask masks values with the hex-encoded HMAC-SHA256 of the value keyed
+// by the key. Strings are hashed as-is and all other values as JSON, so
+// equal values are masked with equal hashes and redacted documents can
+// still be correlated. The key should be secret, as values with few
+// possibilities, such as phone numbers, are otherwise easily recovered
+// from their hashes.
+func HashMask(key []byte) Mask {
+	return func(v interface{}) interface{} {
+		s, ok := v.(string)
+		if !ok {
+			s = formatValue(v)
+		}
+
+		mac := hmac.New(sha256.New, key)
+		mac.Write([]byte(s))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+}
+
+// Redact returns a copy of the document in which the values the selectors
+// match, including those matched by wildcards, are replaced with the value
+// the mask returns for them. Paths that do not exist on the document are
+// ignored rather than created.
+//
+// The document is never modified: the maps, slices and yaml.v3 nodes along
+// the redacted paths are copied as with SetCopy, and everything else is
+// shared between the document and the redacted copy. The mask is given the
+// values of yaml.v3 nodes decoded. Values within a value that is redacted
+// are not redacted separately.
+//
+// Example usage:
+//
+//	email, _ := Parse(".users[*].email")
+//	Redact(doc, MaskWith("[REDACTED]"), email)
+func Redact(doc interface{}, mask Mask, selectors ...*Selector) (interface{}, error) {
+	var matches []*Selector
+	for _, sel := range selectors {
+		m, err := sel.Expand(doc)
+		if err != nil {
+			return nil, err
+		}
+		matches = append(matches, m...)
+	}
+
+	// values within redacted values are redacted along with them.
+	sort.SliceStable(matches, func(i, j int) bool {
+		return len(matches[i].segments()) < len(matches[j].segments())
+	})
+
+	redacted := doc
+	var paths [][]string
+	for _, match := range matches {
+		segments := match.segments()
+		if hasRedactedPrefix(paths, segments) {
+			continue
+		}
+		paths = append(paths, segments)
+
+		v, err := match.Resolve(doc)
+		if err != nil {
+			return nil, err
+		}
+		if redacted, err = match.SetCopy(redacted, mask(decodeYAMLValue(v))); err != nil {
+			return nil, err
+		}
+	}
+	return redacted, nil
+}
+
+// hasRedactedPrefix determines if the segments begin with any of the paths.
+func hasRedactedPrefix(paths [][]string, segments []string) bool {
+	for _, path := range paths {
+		if hasSegmentPrefix(segments, path) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,326 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+func mustParseAll(t *testing.T, selectors ...string) []*Selector {
+	t.Helper()
+
+	sels := make([]*Selector, len(selectors))
+	for i, s := range selectors {
+		sel, err := Parse(s)
+		if err != nil {
+			t.Fatalf("`%s` could not be parsed: %s", s, err)
+		}
+		sels[i] = sel
+	}
+	return sels
+}
+
+func newUsersDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"users": []interface{}{
+			map[string]interface{}{"name": "ann", "email": "ann@example.com", "tags": []interface{}{"admin"}},
+			map[string]interface{}{"name": "bob", "email": "bob@example.com"},
+			"invalid",
+		},
+		"owner": map[string]interface{}{"name": "ann", "email": "ann@example.com"},
+		"count": 2,
+	}
+}
+
+type expandTestFixture struct {
+	selector string
+	expected []string
+}
+
+func runExpandTest(t *testing.T, fixture expandTestFixture) {
+	t.Helper()
+
+	doc := newUsersDoc()
+	sel := mustParseAll(t, fixture.selector)[0]
+	matches, err := sel.Expand(doc)
+	if err != nil {
+		t.Errorf("unexpected error expanding `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	var paths []string
+	for _, match := range matches {
+		paths = append(paths, match.String())
+	}
+	if diff := cmp.Diff(fixture.expected, paths); diff != "" {
+		t.Errorf("`%s` was not expanded as expected:\n%s", fixture.selector, diff)
+	}
+}
+
+func TestSelectorExpand(t *testing.T) {
+	runExpandTest(t, expandTestFixture{
+		selector: ".users[*].email",
+		expected: []string{".users[0].email", ".users[1].email"},
+	})
+
+	runExpandTest(t, expandTestFixture{
+		selector: ".*.name",
+		expected: []string{".owner.name"},
+	})
+
+	runExpandTest(t, expandTestFixture{
+		selector: "*",
+		expected: []string{".count", ".owner", ".users"},
+	})
+
+	runExpandTest(t, expandTestFixture{
+		selector: ".users[*].tags[*]",
+		expected: []string{".users[0].tags[0]"},
+	})
+
+	// selectors without wildcards expand to themselves if they resolve.
+	runExpandTest(t, expandTestFixture{
+		selector: ".owner.email",
+		expected: []string{".owner.email"},
+	})
+
+	runExpandTest(t, expandTestFixture{
+		selector: ".owner.phone",
+		expected: nil,
+	})
+
+	runExpandTest(t, expandTestFixture{
+		selector: ".count[*]",
+		expected: nil,
+	})
+}
+
+func TestSelectorResolve_wildcard(t *testing.T) {
+	sel := mustParseAll(t, ".users[*].email")[0]
+	if s := sel.String(); s != ".users[*].email" {
+		t.Errorf("unexpected canonical selector: %s", s)
+	}
+
+	_, err := sel.Resolve(newUsersDoc())
+	expected := ResolveError{
+		Code: "TypeError",
+		Msg:  "wildcard matches several values; use Expand",
+		Pos:  6,
+		Path: ".users",
+	}
+	if diff := cmp.Diff(expected, err); diff != "" {
+		t.Errorf("unexpected error resolving a wildcard:\n%s", diff)
+	}
+}
+
+type projectTestFixture struct {
+	val       interface{}
+	selectors []string
+	expected  interface{}
+}
+
+func runProjectTest(t *testing.T, fixture projectTestFixture) {
+	t.Helper()
+
+	projection, err := Project(fixture.val, mustParseAll(t, fixture.selectors...)...)
+	if err != nil {
+		t.Errorf("unexpected error projecting %v: %s", fixture.selectors, err)
+		return
+	}
+	if diff := cmp.Diff(fixture.expected, projection); diff != "" {
+		t.Errorf("%v was not projected as expected:\n%s", fixture.selectors, diff)
+	}
+}
+
+func TestProject(t *testing.T) {
+	runProjectTest(t, projectTestFixture{
+		val:       newUsersDoc(),
+		selectors: []string{".users[*].name", ".count"},
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "ann"},
+				map[string]interface{}{"name": "bob"},
+			},
+			"count": 2,
+		},
+	})
+
+	// elements keep their indices.
+	runProjectTest(t, projectTestFixture{
+		val:       newUsersDoc(),
+		selectors: []string{".users[1].email"},
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				nil,
+				map[string]interface{}{"email": "bob@example.com"},
+			},
+		},
+	})
+
+	// overlapping selectors.
+	runProjectTest(t, projectTestFixture{
+		val:       newUsersDoc(),
+		selectors: []string{".owner.name", ".owner"},
+		expected: map[string]interface{}{
+			"owner": map[string]interface{}{"name": "ann", "email": "ann@example.com"},
+		},
+	})
+
+	// missing paths are ignored.
+	runProjectTest(t, projectTestFixture{
+		val:       newUsersDoc(),
+		selectors: []string{".missing", ".users[5]"},
+		expected:  map[string]interface{}{},
+	})
+
+	runProjectTest(t, projectTestFixture{
+		val:       []interface{}{1, 2},
+		selectors: []string{".missing"},
+		expected:  []interface{}{},
+	})
+
+	// the whole document.
+	runProjectTest(t, projectTestFixture{
+		val:       map[string]interface{}{"a": 1},
+		selectors: []string{""},
+		expected:  map[string]interface{}{"a": 1},
+	})
+}
+
+func TestProject_copiesValues(t *testing.T) {
+	doc := newUsersDoc()
+	projection, err := Project(doc, mustParseAll(t, ".owner")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	projection.(map[string]interface{})["owner"].(map[string]interface{})["name"] = "eve"
+	if diff := cmp.Diff(newUsersDoc(), doc); diff != "" {
+		t.Errorf("document was modified:\n%s", diff)
+	}
+}
+
+func TestProject_yaml(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("users:\n  - name: ann\n    email: ann@example.com\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	runProjectTest(t, projectTestFixture{
+		val:       &doc,
+		selectors: []string{".users[*].name"},
+		expected: map[string]interface{}{
+			"users": []interface{}{map[string]interface{}{"name": "ann"}},
+		},
+	})
+}
+
+type redactTestFixture struct {
+	val       interface{}
+	mask      Mask
+	selectors []string
+	expected  interface{}
+}
+
+func runRedactTest(t *testing.T, fixture redactTestFixture) {
+	t.Helper()
+
+	redacted, err := Redact(fixture.val, fixture.mask, mustParseAll(t, fixture.selectors...)...)
+	if err != nil {
+		t.Errorf("unexpected error redacting %v: %s", fixture.selectors, err)
+		return
+	}
+	if diff := cmp.Diff(fixture.expected, redacted); diff != "" {
+		t.Errorf("%v was not redacted as expected:\n%s", fixture.selectors, diff)
+	}
+}
+
+func TestRedact(t *testing.T) {
+	doc := newUsersDoc()
+	runRedactTest(t, redactTestFixture{
+		val:       doc,
+		mask:      MaskWith("[REDACTED]"),
+		selectors: []string{".users[*].email", ".owner.email", ".owner.phone"},
+		expected: map[string]interface{}{
+			"users": []interface{}{
+				map[string]interface{}{"name": "ann", "email": "[REDACTED]", "tags": []interface{}{"admin"}},
+				map[string]interface{}{"name": "bob", "email": "[REDACTED]"},
+				"invalid",
+			},
+			"owner": map[string]interface{}{"name": "ann", "email": "[REDACTED]"},
+			"count": 2,
+		},
+	})
+
+	// the document is not modified.
+	if diff := cmp.Diff(newUsersDoc(), doc); diff != "" {
+		t.Errorf("document was modified:\n%s", diff)
+	}
+
+	// values within redacted values are redacted along with them.
+	runRedactTest(t, redactTestFixture{
+		val:       newUsersDoc(),
+		mask:      MaskWith(nil),
+		selectors: []string{".owner.name", ".owner", ".users"},
+		expected: map[string]interface{}{
+			"users": nil,
+			"owner": nil,
+			"count": 2,
+		},
+	})
+}
+
+func TestRedact_hash(t *testing.T) {
+	redacted, err := Redact(newUsersDoc(), HashMask([]byte("key")), mustParseAll(t, ".users[*].email", ".owner.email", ".count")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	m := redacted.(map[string]interface{})
+	owner := m["owner"].(map[string]interface{})["email"]
+	ann := m["users"].([]interface{})[0].(map[string]interface{})["email"]
+	bob := m["users"].([]interface{})[1].(map[string]interface{})["email"]
+
+	if owner != ann {
+		t.Errorf("equal values were masked with different hashes: %v and %v", owner, ann)
+	}
+	if ann == bob {
+		t.Errorf("different values were masked with the same hash: %v", ann)
+	}
+	if s, ok := ann.(string); !ok || len(s) != 64 {
+		t.Errorf("unexpected hash: %v", ann)
+	}
+	if m["count"] != HashMask([]byte("key"))(2) {
+		t.Errorf("unexpected hash of a number: %v", m["count"])
+	}
+	if ann == HashMask([]byte("other"))("ann@example.com") {
+		t.Errorf("values were masked with the same hash regardless of the key")
+	}
+}
+
+func TestRedact_yaml(t *testing.T) {
+	var doc yaml.Node
+	src := "# users\nusers:\n  - name: ann\n    email: ann@example.com # primary\n"
+	if err := yaml.Unmarshal([]byte(src), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	redacted, err := Redact(&doc, MaskWith("***"), mustParseAll(t, ".users[*].email")...)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	out, err := yaml.Marshal(redacted)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := "# users\nusers:\n    - name: ann\n      email: '***' # primary\n"
+	if diff := cmp.Diff(expected, string(out)); diff != "" {
+		t.Errorf("document was not redacted as expected:\n%s", diff)
+	}
+
+	if out, _ := yaml.Marshal(&doc); string(out) != "# users\nusers:\n    - name: ann\n      email: ann@example.com # primary\n" {
+		t.Errorf("document was modified:\n%s", out)
+	}
+}
@@ -0,0 +1,176 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// PathError represents a required path that is missing or cannot be
+// resolved.
+type PathError struct {
+	// Path is the canonical selector of the required path.
+	Path *Selector
+
+	// Found describes the value found where resolving the path failed:
+	// "nothing" for a missing key or index, "null" for a null value, or the
+	// type of the value, e.g. "string", that a key or index cannot be
+	// selected on.
+	Found string
+
+	// Err is the underlying ResolveError, positioned in the canonical
+	// key-path of Path.
+	Err error
+}
+
+// PathError implements (error).Error
+func (e *PathError) Error() string {
+	return fmt.Sprintf("%s: %s (found %s)", e.Path, e.Err, e.Found)
+}
+
+// Unwrap returns the underlying error.
+func (e *PathError) Unwrap() error {
+	return e.Err
+}
+
+// Diagnostic formats the error with the path and a caret pointing at the
+// step resolving it failed at, as by Diagnose:
+//
+//	.server.host.name
+//	            ^
+//	TypeError: cannot resolve attribute 'name' on type string (found string)
+func (e *PathError) Diagnostic() string {
+	return Diagnose(e.Path.String(), fmt.Errorf("%w (found %s)", e.Err, e.Found))
+}
+
+// RequireError represents the required paths that are missing or cannot be
+// resolved.
+type RequireError []*PathError
+
+// RequireError implements (error).Error
+func (l RequireError) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d required paths are missing or invalid:", len(l))
+	for _, err := range l {
+		b.WriteString("\n  ")
+		b.WriteString(err.Error())
+	}
+	return b.String()
+}
+
+// Diagnostics returns the diagnostics of the errors, separated by blank
+// lines.
+func (l RequireError) Diagnostics() string {
+	diagnostics := make([]string, len(l))
+	for i, err := range l {
+		diagnostics[i] = err.Diagnostic()
+	}
+	return strings.Join(diagnostics, "\n\n")
+}
+
+// Push adds a new path error to the collection.
+func (l *RequireError) Push(err *PathError) {
+	*l = append(*l, err)
+}
+
+// Require resolves every selector on the document and reports the paths
+// that are missing, null, or cannot be resolved, e.g. as they select a key
+// on a string. Unlike Selector.Resolve, it does not stop at the first error:
+// the errors of all paths are returned together as a RequireError, in the
+// order of the selectors, so that they can be fixed at once.
+//
+// Example usage:
+//
+//	if err := Require(config, host, port, tlsCert); err != nil {
+//		var reqErr RequireError
+//		if errors.As(err, &reqErr) {
+//			log.Fatal(reqErr.Diagnostics())
+//		}
+//	}
+func Require(doc interface{}, selectors ...*Selector) error {
+	var errs RequireError
+	for _, sel := range selectors {
+		if err := require(doc, sel); err != nil {
+			errs.Push(err)
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// require resolves the path of the selector on the document, returning a
+// PathError if it is missing, null, or cannot be resolved.
+func require(doc interface{}, sel *Selector) *PathError {
+	// errors are positioned in the canonical key-path, as the key-path the
+	// selector was parsed from is not retained.
+	path := selectorFromKeys(sel.keys())
+
+	v, err := path.Resolve(doc, Strict())
+	if err == nil {
+		if decodeYAMLValue(v) != nil {
+			return nil
+		}
+		return &PathError{
+			Path:  path,
+			Found: "null",
+			Err: ResolveError{
+				Code: "ValueError",
+				Msg:  "required value is null",
+				Pos:  path.lastPos(),
+				Path: path.Parent().String(),
+			},
+		}
+	}
+
+	found := "nothing"
+	if e, ok := err.(ResolveError); ok && e.Code == "TypeError" {
+		// the value at the path resolved before the error is the value the
+		// next key or index cannot be selected on.
+		parent, _ := Parse(e.Path)
+		if pv, err := parent.Resolve(doc); err == nil {
+			found = typeName(pv)
+		}
+	}
+	return &PathError{Path: path, Found: found, Err: err}
+}
+
+// typeName returns the name of the type of the value as in JSON and YAML,
+// e.g. "string", "number" or "map".
+func typeName(v interface{}) string {
+	v = decodeYAMLValue(v)
+	if v == nil {
+		return "null"
+	}
+	if _, ok := v.(json.Number); ok {
+		return "number"
+	}
+
+	switch rv := indirect(reflect.ValueOf(v)); rv.Kind() {
+	case reflect.Invalid:
+		return "null"
+	case reflect.String:
+		return "string"
+	case reflect.Bool:
+		return "bool"
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		return "number"
+	case reflect.Map, reflect.Struct:
+		return "map"
+	case reflect.Slice, reflect.Array:
+		return "list"
+	}
+	return fmt.Sprintf("%T", v)
+}
@@ -0,0 +1,128 @@
+package selectr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type requireTestFixture struct {
+	val       interface{}
+	selectors []string
+	expected  []string
+}
+
+func runRequireTest(t *testing.T, fixture requireTestFixture) {
+	t.Helper()
+
+	err := Require(fixture.val, mustParseAll(t, fixture.selectors...)...)
+	if fixture.expected == nil {
+		if err != nil {
+			t.Errorf("unexpected error requiring %v: %s", fixture.selectors, err)
+		}
+		return
+	}
+
+	var reqErr RequireError
+	if !errors.As(err, &reqErr) {
+		t.Fatalf("expected a RequireError requiring %v but got: %v", fixture.selectors, err)
+	}
+
+	var diagnostics []string
+	for _, e := range reqErr {
+		diagnostics = append(diagnostics, e.Diagnostic())
+	}
+	if diff := cmp.Diff(fixture.expected, diagnostics); diff != "" {
+		t.Errorf("unexpected diagnostics requiring %v:\n%s", fixture.selectors, diff)
+	}
+}
+
+func TestRequire(t *testing.T) {
+	doc := newConfigDoc()
+	doc["proxy"] = nil
+
+	runRequireTest(t, requireTestFixture{
+		val:       doc,
+		selectors: []string{".server.host", ".server.tls", ".workers[1].debug"},
+		expected:  nil,
+	})
+
+	runRequireTest(t, requireTestFixture{
+		val: doc,
+		selectors: []string{
+			".server.host",
+			"server.host.name",
+			".server.prot",
+			".workers[5].name",
+			".proxy",
+			"database['pool'][0]",
+		},
+		expected: []string{
+			".server.host.name\n" +
+				"            ^\n" +
+				"TypeError: cannot resolve attribute 'name' on type string (found string)",
+			".server.prot\n" +
+				"       ^\n" +
+				"KeyError: key 'prot' not found in '.server'; did you mean 'port'? (found nothing)",
+			".workers[5].name\n" +
+				"        ^\n" +
+				"IndexError: index out of range; index is 5 but length is only 2 (found nothing)",
+			".proxy\n" +
+				"^\n" +
+				"ValueError: required value is null (found null)",
+			".database.pool[0]\n" +
+				"              ^\n" +
+				"TypeError: cannot resolve element '0' on type int (found number)",
+		},
+	})
+}
+
+func TestRequire_yaml(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("server:\n  host: localhost\n  port: ~\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	runRequireTest(t, requireTestFixture{
+		val:       &doc,
+		selectors: []string{".server.host", ".server.port", ".server.host[0]"},
+		expected: []string{
+			".server.port\n" +
+				"       ^\n" +
+				"ValueError: required value is null (found null)",
+			".server.host[0]\n" +
+				"            ^\n" +
+				"TypeError: cannot resolve element '0' on YAML scalar node (found string)",
+		},
+	})
+}
+
+func TestRequireError(t *testing.T) {
+	err := Require(newConfigDoc(), mustParseAll(t, ".server.tls.ca", ".cache")...)
+
+	expected := "2 required paths are missing or invalid:\n" +
+		"  .server.tls.ca: KeyError: key 'ca' not found in '.server.tls' (found nothing)\n" +
+		"  .cache: KeyError: key 'cache' not found (found nothing)"
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error:\n%v", err)
+	}
+
+	expected = ".server.tls.ca\n" +
+		"           ^\n" +
+		"KeyError: key 'ca' not found in '.server.tls' (found nothing)\n" +
+		"\n" +
+		".cache\n" +
+		"^\n" +
+		"KeyError: key 'cache' not found (found nothing)"
+	if diff := cmp.Diff(expected, err.(RequireError).Diagnostics()); diff != "" {
+		t.Errorf("unexpected diagnostics:\n%s", diff)
+	}
+
+	// the underlying errors can be inspected.
+	var resolveErr ResolveError
+	if !errors.As(err.(RequireError)[0], &resolveErr) || resolveErr.Code != "KeyError" {
+		t.Errorf("expected the underlying ResolveError but got: %v", resolveErr)
+	}
+}
@@ -0,0 +1,371 @@
+package selectr
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Checker checks a value of a document.
+type Checker interface {
+	// Check returns an error describing why the value is invalid, or nil.
+	Check(v interface{}) error
+}
+
+// CheckerFunc adapts a function to a Checker.
+type CheckerFunc func(v interface{}) error
+
+// Check calls f(v).
+func (f CheckerFunc) Check(v interface{}) error {
+	return f(v)
+}
+
+// Rules maps selector patterns to the checkers of the values they match.
+// Patterns may contain wildcards, `.*` and `[*]`, to check every entry of a
+// map or element of a slice:
+//
+//	rules := Rules{
+//		".port":     IntRange(1, 65535),
+//		".hosts[*]": Hostname(),
+//		".mode":     OneOf("a", "b"),
+//	}
+//	err := rules.Validate(config)
+//
+// Rules can also be read from YAML, naming registered checkers (see
+// RegisterChecker) with their arguments, or a list of them:
+//
+//	.port: {intRange: [1, 65535]}
+//	.hosts[*]: hostname
+//	.mode: [{oneOf: [a, b]}]
+type Rules map[string]Checker
+
+// Violation represents a value that does not pass the checker of a rule.
+type Violation struct {
+	// Path is the canonical selector of the value.
+	Path *Selector
+
+	// Rule is the pattern of the rule the value matched.
+	Rule string
+
+	Err error
+}
+
+// Violation implements (error).Error
+func (v *Violation) Error() string {
+	return fmt.Sprintf("%s: %s", v.Path, v.Err)
+}
+
+// Unwrap returns the error of the checker.
+func (v *Violation) Unwrap() error {
+	return v.Err
+}
+
+// ValidationError represents the violations of the rules by a document.
+type ValidationError []*Violation
+
+// ValidationError implements (error).Error
+func (l ValidationError) Error() string {
+	switch len(l) {
+	case 0:
+		return "no errors"
+	case 1:
+		return l[0].Error()
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d values are invalid:", len(l))
+	for _, v := range l {
+		b.WriteString("\n  ")
+		b.WriteString(v.Error())
+	}
+	return b.String()
+}
+
+// Push adds a new violation to the collection.
+func (l *ValidationError) Push(v *Violation) {
+	*l = append(*l, v)
+}
+
+// ByPath returns the violations keyed by the canonical selectors of their
+// values.
+func (l ValidationError) ByPath() map[string][]*Violation {
+	m := make(map[string][]*Violation, len(l))
+	for _, v := range l {
+		path := v.Path.String()
+		m[path] = append(m[path], v)
+	}
+	return m
+}
+
+// Validate checks the values of the document matched by the pattern of each
+// rule, expanding wildcards as Expand does. Rules do not apply to values
+// that do not exist; use Require to check that they do. yaml.v3 nodes are
+// decoded before they are checked.
+//
+// The violations of all rules are returned together as a ValidationError,
+// ordered by the patterns of the rules, then in the order of Expand. An
+// error is returned instead if a pattern cannot be parsed.
+func (r Rules) Validate(doc interface{}) error {
+	patterns := make([]string, 0, len(r))
+	for pattern := range r {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+
+	var errs ValidationError
+	for _, pattern := range patterns {
+		sel, err := Parse(pattern)
+		if err != nil {
+			return fmt.Errorf("invalid rule '%s': %w", pattern, err)
+		}
+
+		matches, err := sel.Expand(doc)
+		if err != nil {
+			return err
+		}
+		for _, match := range matches {
+			v, err := match.Resolve(doc)
+			if err != nil {
+				return err
+			}
+			if err := r[pattern].Check(decodeYAMLValue(v)); err != nil {
+				errs.Push(&Violation{Path: match, Rule: pattern, Err: err})
+			}
+		}
+	}
+
+	if len(errs) != 0 {
+		return errs
+	}
+	return nil
+}
+
+// IntRange checks that values are integers between min and max, inclusive.
+// Floats holding an exact integer, as decoded by encoding/json, are
+// integers.
+func IntRange(min, max int) Checker {
+	return CheckerFunc(func(v interface{}) error {
+		rv, err := convert(v, reflect.TypeOf(0))
+		if err != nil {
+			return fmt.Errorf("expected an integer but got %s", formatValue(v))
+		}
+		if n := int(rv.Int()); n < min || n > max {
+			return fmt.Errorf("%d is out of range [%d, %d]", n, min, max)
+		}
+		return nil
+	})
+}
+
+// Hostname checks that values are hostnames as defined by RFC 1123: dot
+// separated labels of letters, digits and hyphens, not beginning or ending
+// with a hyphen, of at most 63 characters, and of at most 253 characters
+// in total.
+func Hostname() Checker {
+	return CheckerFunc(func(v interface{}) error {
+		s, ok := v.(string)
+		if !ok {
+			return fmt.Errorf("expected a hostname but got %s", formatValue(v))
+		}
+		if !isHostname(s) {
+			return fmt.Errorf("'%s' is not a valid hostname", s)
+		}
+		return nil
+	})
+}
+
+// isHostname determines if s is a hostname as defined by RFC 1123.
+func isHostname(s string) bool {
+	if s == "" || len(s) > 253 {
+		return false
+	}
+	for _, label := range strings.Split(s, ".") {
+		if label == "" || len(label) > 63 || label[0] == '-' || label[len(label)-1] == '-' {
+			return false
+		}
+		for _, ch := range label {
+			isAlnum := (ch >= 'a' && ch <= 'z') || (ch >= 'A' && ch <= 'Z') || (ch >= '0' && ch <= '9')
+			if !isAlnum && ch != '-' {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// OneOf checks that values equal one of the values. Numbers are equal if
+// their values are, regardless of their types.
+func OneOf(values ...interface{}) Checker {
+	return CheckerFunc(func(v interface{}) error {
+		for _, allowed := range values {
+			if valuesEqual(v, allowed) {
+				return nil
+			}
+		}
+
+		formatted := make([]string, len(values))
+		for i, allowed := range values {
+			formatted[i] = formatValue(allowed)
+		}
+		return fmt.Errorf("%s is not one of %s", formatValue(v), strings.Join(formatted, ", "))
+	})
+}
+
+// All checks that values pass every checker, returning the error of the
+// first one they do not pass.
+func All(checkers ...Checker) Checker {
+	return CheckerFunc(func(v interface{}) error {
+		for _, c := range checkers {
+			if err := c.Check(v); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CheckerFactory returns a checker configured by the arguments given to it
+// in YAML rules.
+type CheckerFactory func(args ...interface{}) (Checker, error)
+
+var (
+	checkersMu sync.RWMutex
+	checkers   = map[string]CheckerFactory{}
+)
+
+// RegisterChecker makes a checker available by name to rules read from
+// YAML. It panics if a checker is already registered by the name, or if the
+// factory is nil. The checkers IntRange (`intRange: [min, max]`), Hostname
+// (`hostname`) and OneOf (`oneOf: [values...]`) are registered by default.
+func RegisterChecker(name string, factory CheckerFactory) {
+	checkersMu.Lock()
+	defer checkersMu.Unlock()
+
+	if factory == nil {
+		panic("selectr: RegisterChecker factory is nil")
+	}
+	if _, ok := checkers[name]; ok {
+		panic("selectr: RegisterChecker called twice for checker " + name)
+	}
+	checkers[name] = factory
+}
+
+func init() {
+	RegisterChecker("intRange", func(args ...interface{}) (Checker, error) {
+		if len(args) != 2 {
+			return nil, fmt.Errorf("expected 2 arguments but got %d", len(args))
+		}
+		var bounds [2]int
+		for i, arg := range args {
+			rv, err := convert(arg, reflect.TypeOf(0))
+			if err != nil {
+				return nil, fmt.Errorf("expected an integer but got %s", formatValue(arg))
+			}
+			bounds[i] = int(rv.Int())
+		}
+		return IntRange(bounds[0], bounds[1]), nil
+	})
+
+	RegisterChecker("hostname", func(args ...interface{}) (Checker, error) {
+		if len(args) != 0 {
+			return nil, fmt.Errorf("expected no arguments but got %d", len(args))
+		}
+		return Hostname(), nil
+	})
+
+	RegisterChecker("oneOf", func(args ...interface{}) (Checker, error) {
+		return OneOf(args...), nil
+	})
+}
+
+// ParseRules reads rules from YAML (or JSON), as described by Rules.
+func ParseRules(data []byte) (Rules, error) {
+	var rules Rules
+	if err := yaml.Unmarshal(data, &rules); err != nil {
+		return nil, err
+	}
+	return rules, nil
+}
+
+// UnmarshalYAML reads rules from a YAML mapping of selector patterns to
+// checkers, as described by Rules, so that rules can be embedded in other
+// YAML documents.
+func (r *Rules) UnmarshalYAML(n *yaml.Node) error {
+	var raw map[string]interface{}
+	if err := n.Decode(&raw); err != nil {
+		return err
+	}
+
+	rules := make(Rules, len(raw))
+	for pattern, spec := range raw {
+		if _, err := Parse(pattern); err != nil {
+			return fmt.Errorf("invalid rule '%s': %w", pattern, err)
+		}
+
+		c, err := newChecker(spec)
+		if err != nil {
+			return fmt.Errorf("invalid rule '%s': %w", pattern, err)
+		}
+		rules[pattern] = c
+	}
+	*r = rules
+	return nil
+}
+
+// newChecker returns the checker of the YAML specification: the name of a
+// registered checker, a mapping of the name to its arguments, or a list of
+// those.
+func newChecker(spec interface{}) (Checker, error) {
+	switch spec := spec.(type) {
+	case string:
+		return lookupChecker(spec, nil)
+
+	case map[string]interface{}:
+		if len(spec) != 1 {
+			return nil, fmt.Errorf("expected a single checker but got %d", len(spec))
+		}
+		for name, args := range spec {
+			var list []interface{}
+			switch args := args.(type) {
+			case nil:
+			case []interface{}:
+				list = args
+			default:
+				list = []interface{}{args}
+			}
+			return lookupChecker(name, list)
+		}
+
+	case []interface{}:
+		all := make([]Checker, len(spec))
+		for i, s := range spec {
+			c, err := newChecker(s)
+			if err != nil {
+				return nil, err
+			}
+			all[i] = c
+		}
+		return All(all...), nil
+	}
+	return nil, fmt.Errorf("expected a checker but got %s", formatValue(spec))
+}
+
+// lookupChecker returns the checker registered by the name configured by
+// the arguments.
+func lookupChecker(name string, args []interface{}) (Checker, error) {
+	checkersMu.RLock()
+	factory, ok := checkers[name]
+	checkersMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("unknown checker '%s'", name)
+	}
+
+	c, err := factory(args...)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", name, err)
+	}
+	return c, nil
+}
@@ -0,0 +1,218 @@
+package selectr
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type rulesTestFixture struct {
+	rules    Rules
+	val      interface{}
+	expected []string
+}
+
+func runRulesTest(t *testing.T, fixture rulesTestFixture) {
+	t.Helper()
+
+	err := fixture.rules.Validate(fixture.val)
+	if fixture.expected == nil {
+		if err != nil {
+			t.Errorf("unexpected error validating: %s", err)
+		}
+		return
+	}
+
+	var validationErr ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a ValidationError but got: %v", err)
+	}
+
+	var violations []string
+	for _, v := range validationErr {
+		violations = append(violations, v.Error())
+	}
+	if diff := cmp.Diff(fixture.expected, violations); diff != "" {
+		t.Errorf("unexpected violations:\n%s", diff)
+	}
+}
+
+func newServiceDoc() interface{} {
+	var doc interface{}
+	json.Unmarshal([]byte(`{
+		"port": 8080,
+		"hosts": ["example.com", "api.example.com"],
+		"mode": "a",
+		"replicas": [{"port": 80}, {"port": 443}]
+	}`), &doc)
+	return doc
+}
+
+func TestRulesValidate(t *testing.T) {
+	rules := Rules{
+		".port":             IntRange(1, 65535),
+		".hosts[*]":         Hostname(),
+		".mode":             OneOf("a", "b"),
+		".replicas[*].port": IntRange(1, 1023),
+		".missing":          Hostname(),
+	}
+
+	runRulesTest(t, rulesTestFixture{
+		rules:    rules,
+		val:      newServiceDoc(),
+		expected: nil,
+	})
+
+	doc := newServiceDoc().(map[string]interface{})
+	doc["port"] = 70000
+	doc["hosts"] = []interface{}{"example.com", "-bad-.com", "a..b", 42, strings.Repeat("a", 64) + ".com"}
+	doc["mode"] = "c"
+	doc["replicas"] = []interface{}{map[string]interface{}{"port": 8080}, map[string]interface{}{"port": 80.5}}
+
+	runRulesTest(t, rulesTestFixture{
+		rules: rules,
+		val:   doc,
+		expected: []string{
+			".hosts[1]: '-bad-.com' is not a valid hostname",
+			".hosts[2]: 'a..b' is not a valid hostname",
+			".hosts[3]: expected a hostname but got 42",
+			".hosts[4]: '" + strings.Repeat("a", 64) + ".com' is not a valid hostname",
+			`.mode: "c" is not one of "a", "b"`,
+			".port: 70000 is out of range [1, 65535]",
+			".replicas[0].port: 8080 is out of range [1, 1023]",
+			".replicas[1].port: expected an integer but got 80.5",
+		},
+	})
+}
+
+func TestRulesValidate_custom(t *testing.T) {
+	even := CheckerFunc(func(v interface{}) error {
+		if n, ok := v.(int); !ok || n%2 != 0 {
+			return fmt.Errorf("%v is not even", v)
+		}
+		return nil
+	})
+
+	runRulesTest(t, rulesTestFixture{
+		rules: Rules{"[*]": All(IntRange(0, 10), even)},
+		val:   []interface{}{2, 3, 12},
+		expected: []string{
+			"[1]: 3 is not even",
+			"[2]: 12 is out of range [0, 10]",
+		},
+	})
+}
+
+func TestRulesValidate_invalidPattern(t *testing.T) {
+	err := Rules{".a[": Hostname()}.Validate(nil)
+	if err == nil || !strings.HasPrefix(err.Error(), "invalid rule '.a[': ") {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidationError(t *testing.T) {
+	err := Rules{".a[*]": OneOf(1)}.Validate(map[string]interface{}{"a": []interface{}{1, 2, 3}})
+
+	expected := "2 values are invalid:\n" +
+		"  .a[1]: 2 is not one of 1\n" +
+		"  .a[2]: 3 is not one of 1"
+	if err == nil || err.Error() != expected {
+		t.Errorf("unexpected error:\n%v", err)
+	}
+
+	byPath := err.(ValidationError).ByPath()
+	if len(byPath) != 2 || len(byPath[".a[2]"]) != 1 || byPath[".a[2]"][0].Rule != ".a[*]" {
+		t.Errorf("unexpected violations by path: %v", byPath)
+	}
+}
+
+func TestParseRules(t *testing.T) {
+	rules, err := ParseRules([]byte(`
+.port: {intRange: [1, 65535]}
+.hosts[*]: hostname
+.mode: [{oneOf: [a, b]}, {oneOf: a}]
+`))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	runRulesTest(t, rulesTestFixture{
+		rules: rules,
+		val:   newServiceDoc(),
+	})
+
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("port: 0\nhosts: [ok, not_ok]\nmode: b\n"), &doc)
+	runRulesTest(t, rulesTestFixture{
+		rules: rules,
+		val:   &doc,
+		expected: []string{
+			".hosts[1]: 'not_ok' is not a valid hostname",
+			`.mode: "b" is not one of "a"`,
+			".port: 0 is out of range [1, 65535]",
+		},
+	})
+}
+
+func TestParseRules_embedded(t *testing.T) {
+	var config struct {
+		Rules Rules `yaml:"rules"`
+	}
+	if err := yaml.Unmarshal([]byte("rules:\n  .mode: {oneOf: [a]}\n"), &config); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if err := config.Rules.Validate(map[string]interface{}{"mode": "b"}); err == nil {
+		t.Errorf("expected a violation")
+	}
+}
+
+func TestParseRules_errors(t *testing.T) {
+	for src, expected := range map[string]string{
+		".a: unknown":                 "invalid rule '.a': unknown checker 'unknown'",
+		".a: {intRange: [1]}":         "invalid rule '.a': intRange: expected 2 arguments but got 1",
+		".a: {intRange: [1, x]}":      `invalid rule '.a': intRange: expected an integer but got "x"`,
+		".a: {hostname: 1}":           "invalid rule '.a': hostname: expected no arguments but got 1",
+		".a: {oneOf: [1], extra: []}": "invalid rule '.a': expected a single checker but got 2",
+		".a: 3":                       "invalid rule '.a': expected a checker but got 3",
+		"'.a[': hostname":             "invalid rule '.a[': ",
+	} {
+		_, err := ParseRules([]byte(src))
+		if err == nil || !strings.HasPrefix(err.Error(), expected) {
+			t.Errorf("expected error %q parsing `%s` but got: %v", expected, src, err)
+		}
+	}
+}
+
+func TestRegisterChecker(t *testing.T) {
+	RegisterChecker("testPrefix", func(args ...interface{}) (Checker, error) {
+		prefix, _ := args[0].(string)
+		return CheckerFunc(func(v interface{}) error {
+			if s, _ := v.(string); !strings.HasPrefix(s, prefix) {
+				return fmt.Errorf("expected prefix %s", prefix)
+			}
+			return nil
+		}), nil
+	})
+
+	rules, err := ParseRules([]byte(".name: {testPrefix: svc-}"))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	runRulesTest(t, rulesTestFixture{
+		rules:    rules,
+		val:      map[string]interface{}{"name": "web"},
+		expected: []string{".name: expected prefix svc-"},
+	})
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected registering a checker twice to panic")
+		}
+	}()
+	RegisterChecker("hostname", func(args ...interface{}) (Checker, error) { return Hostname(), nil })
+}
@@ -0,0 +1,441 @@
+package selectr
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// CheckSelector statically verifies that the selector can match a value of
+// documents valid against the JSON Schema, without resolving it on a
+// document. It returns the types the schema allows for the value at the end
+// of the path, e.g. []string{"integer"}, or nil if any type is allowed.
+//
+// The schema is given decoded, e.g. by encoding/json or yaml.v3. Steps are
+// followed through `properties`, `patternProperties` and
+// `additionalProperties` of objects, `items`, `prefixItems`,
+// `additionalItems` and `maxItems` of arrays, local `$ref`s (e.g.
+// `#/$defs/user`), and every alternative of `oneOf` and `anyOf`; a step is
+// possible if any alternative allows it. Other keywords, such as `allOf`,
+// are not followed and do not constrain the selector.
+//
+// If the selector cannot match, a ResolveError positioned at the step that
+// cannot be taken is returned: a KeyError for keys the schema does not
+// allow, with the closest declared properties as suggestions, an IndexError
+// for indices beyond the items the schema allows, and a TypeError for steps
+// on values of the wrong type. Errors in the schema itself, such as
+// unresolvable `$ref`s, are ValueErrors.
+//
+// Example usage:
+//
+//	sel, _ := Parse(".server.prot")
+//	_, err := CheckSelector(schema, sel)
+//	// => KeyError: key 'prot' not found in '.server'; did you mean 'port'?
+func CheckSelector(schema interface{}, sel *Selector) ([]string, error) {
+	c := &schemaChecker{root: decodeYAMLValue(schema)}
+
+	schemas, err := c.expand(c.root, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for curr := sel.tree; curr != nil; curr = curr.Child {
+		pos := curr.Resolver.Expression().StartPos()
+
+		var next []interface{}
+		s := &schemaStep{key: stepKey(curr.Resolver)}
+		for _, schema := range schemas {
+			for _, child := range s.take(schema) {
+				expanded, err := c.expand(child, pos, nil)
+				if err != nil {
+					return nil, err
+				}
+				next = append(next, expanded...)
+			}
+		}
+
+		if len(next) == 0 {
+			err := s.err(schemas, pos)
+			err.Path = pathString(sel.tree, curr)
+			if err.Code == "KeyError" {
+				err.Msg = keyNotFoundMsg(s.key, err.Path, err.Suggestions)
+			}
+			return nil, err
+		}
+		schemas = next
+	}
+
+	return schemaTypes(schemas), nil
+}
+
+// schemaChecker follows the steps of selectors through a JSON Schema.
+type schemaChecker struct {
+	root interface{}
+}
+
+// expand returns the schemas a value valid against the schema may be valid
+// against: the schema itself, the schema its `$ref` refers to, or the
+// alternatives of its `oneOf` and `anyOf`, each merged with the schema.
+// Schemas allowing no value are omitted. refs holds the references followed
+// to the schema, to detect cycles.
+func (c *schemaChecker) expand(schema interface{}, pos int, refs []string) ([]interface{}, error) {
+	switch s := schema.(type) {
+	case bool:
+		if s {
+			return []interface{}{s}, nil
+		}
+		return nil, nil
+
+	case map[string]interface{}:
+		if ref, ok := s["$ref"].(string); ok {
+			for _, r := range refs {
+				if r == ref {
+					return nil, errSchema(pos, "circular $ref '%s'", ref)
+				}
+			}
+
+			target, err := c.resolveRef(ref)
+			if err != nil {
+				return nil, errSchema(pos, "cannot resolve $ref '%s': %s", ref, err)
+			}
+			return c.expand(target, pos, append(refs[:len(refs):len(refs)], ref))
+		}
+
+		for _, keyword := range []string{"oneOf", "anyOf"} {
+			alternatives, ok := s[keyword].([]interface{})
+			if !ok {
+				continue
+			}
+
+			var expanded []interface{}
+			for _, alt := range alternatives {
+				e, err := c.expand(mergeSchemas(s, keyword, alt), pos, refs)
+				if err != nil {
+					return nil, err
+				}
+				expanded = append(expanded, e...)
+			}
+			return expanded, nil
+		}
+		return []interface{}{s}, nil
+	}
+	return nil, errSchema(pos, "invalid schema %s", formatValue(schema))
+}
+
+// resolveRef returns the schema a local reference, e.g. `#/$defs/user`,
+// refers to.
+func (c *schemaChecker) resolveRef(ref string) (interface{}, error) {
+	if !strings.HasPrefix(ref, "#") {
+		return nil, fmt.Errorf("only local references are supported")
+	}
+
+	tokens, err := splitJSONPointer(ref[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	// tokens select keys of schemas and indices of lists of schemas.
+	v := c.root
+	for _, token := range tokens {
+		switch node := v.(type) {
+		case map[string]interface{}:
+			var ok bool
+			if v, ok = node[token]; !ok {
+				return nil, fmt.Errorf("key '%s' not found", token)
+			}
+		case []interface{}:
+			i, err := strconv.Atoi(token)
+			if err != nil || i < 0 || i >= len(node) {
+				return nil, fmt.Errorf("invalid index '%s'", token)
+			}
+			v = node[i]
+		default:
+			return nil, fmt.Errorf("cannot resolve '%s' on %s", token, formatValue(v))
+		}
+	}
+	return v, nil
+}
+
+// mergeSchemas returns the alternative of the keyword of the schema merged
+// with the other keywords of the schema. The properties of both are merged;
+// all other keywords of the alternative take precedence.
+func mergeSchemas(schema map[string]interface{}, keyword string, alt interface{}) interface{} {
+	altSchema, ok := alt.(map[string]interface{})
+	if !ok {
+		if alt == true {
+			altSchema = map[string]interface{}{}
+		} else {
+			return alt
+		}
+	}
+
+	merged := make(map[string]interface{}, len(schema)+len(altSchema))
+	for k, v := range schema {
+		if k != keyword {
+			merged[k] = v
+		}
+	}
+	for k, v := range altSchema {
+		merged[k] = v
+	}
+
+	parentProps, ok1 := schema["properties"].(map[string]interface{})
+	altProps, ok2 := altSchema["properties"].(map[string]interface{})
+	if ok1 && ok2 {
+		props := make(map[string]interface{}, len(parentProps)+len(altProps))
+		for k, v := range parentProps {
+			props[k] = v
+		}
+		for k, v := range altProps {
+			props[k] = v
+		}
+		merged["properties"] = props
+	}
+	return merged
+}
+
+// schemaStep represents a step of a selector taken on schemas.
+type schemaStep struct {
+	// key is the key, the index or the wildcard of the step.
+	key interface{}
+
+	// properties are the properties declared by the object schemas the
+	// step was taken on, and maxItems the most items allowed by the array
+	// schemas limiting their number of items.
+	properties []string
+	maxItems   int
+
+	// compatible is true if a schema the step was taken on allows values
+	// of the type the step selects on.
+	compatible bool
+}
+
+// take returns the schemas of the values the step selects on values valid
+// against the schema.
+func (s *schemaStep) take(schema interface{}) []interface{} {
+	m, ok := schema.(map[string]interface{})
+	if !ok {
+		// the schema allows any value.
+		s.compatible = true
+		return []interface{}{true}
+	}
+
+	var children []interface{}
+	types := schemaTypes([]interface{}{m})
+	if _, isIndex := s.key.(int); !isIndex && allowsType(types, "object") {
+		s.compatible = true
+		children = append(children, s.takeObject(m)...)
+	}
+	if _, isKey := s.key.(string); !isKey && allowsType(types, "array") {
+		s.compatible = true
+		children = append(children, s.takeArray(m)...)
+	}
+	return children
+}
+
+// takeObject returns the schemas of the values the step selects on objects
+// valid against the schema.
+func (s *schemaStep) takeObject(schema map[string]interface{}) []interface{} {
+	props, _ := schema["properties"].(map[string]interface{})
+	patterns, _ := schema["patternProperties"].(map[string]interface{})
+	additional, ok := schema["additionalProperties"]
+	if !ok {
+		additional = true
+	}
+
+	key, isKey := s.key.(string)
+	if !isKey {
+		// a wildcard selects every property.
+		var children []interface{}
+		for _, name := range sortedSchemaKeys(props) {
+			children = append(children, props[name])
+		}
+		for _, pattern := range sortedSchemaKeys(patterns) {
+			children = append(children, patterns[pattern])
+		}
+		return append(children, additional)
+	}
+
+	for name := range props {
+		s.properties = append(s.properties, name)
+	}
+	if child, ok := props[key]; ok {
+		return []interface{}{child}
+	}
+
+	var children []interface{}
+	for _, pattern := range sortedSchemaKeys(patterns) {
+		if re, err := regexp.Compile(pattern); err == nil && re.MatchString(key) {
+			children = append(children, patterns[pattern])
+		}
+	}
+	if len(children) != 0 {
+		return children
+	}
+	return []interface{}{additional}
+}
+
+// takeArray returns the schemas of the values the step selects on arrays
+// valid against the schema.
+func (s *schemaStep) takeArray(schema map[string]interface{}) []interface{} {
+	// prefixItems and items describe the first items and the items after
+	// them, and items and additionalItems before draft 2020-12.
+	prefix, rest := schema["prefixItems"], schema["items"]
+	if _, ok := prefix.([]interface{}); !ok {
+		prefix, rest = rest, schema["additionalItems"]
+	}
+	tuple, isTuple := prefix.([]interface{})
+	if !isTuple {
+		tuple, rest = nil, prefix
+	}
+	if rest == nil {
+		rest = true
+	}
+
+	maxItems := -1
+	if n, ok := toFloat64(schema["maxItems"], reflect.ValueOf(schema["maxItems"])); ok {
+		maxItems = int(n)
+	}
+	if rest == false && (maxItems < 0 || len(tuple) < maxItems) {
+		maxItems = len(tuple)
+	}
+	if maxItems > s.maxItems {
+		s.maxItems = maxItems
+	}
+
+	i, isIndex := s.key.(int)
+	if !isIndex {
+		// a wildcard selects every item.
+		return append(append([]interface{}{}, tuple...), rest)
+	}
+	if maxItems >= 0 && i >= maxItems {
+		return nil
+	}
+	if i < len(tuple) {
+		return []interface{}{tuple[i]}
+	}
+	return []interface{}{rest}
+}
+
+// err returns the error of the step not being possible on any of the
+// schemas.
+func (s *schemaStep) err(schemas []interface{}, pos int) ResolveError {
+	switch key := s.key.(type) {
+	case string:
+		if s.compatible {
+			sort.Strings(s.properties)
+			return ResolveError{
+				Code:        "KeyError",
+				Pos:         pos,
+				Suggestions: suggestKeys(key, s.properties),
+			}
+		}
+		return errSchemaType(pos, fmt.Sprintf("attribute '%s'", key), schemas)
+
+	case int:
+		if s.compatible {
+			return ResolveError{
+				Code: "IndexError",
+				Msg:  fmt.Sprintf("index out of range; schema allows at most %d items", s.maxItems),
+				Pos:  pos,
+			}
+		}
+		return errSchemaType(pos, fmt.Sprintf("element '%d'", key), schemas)
+	}
+
+	if s.compatible {
+		return ResolveError{Code: "ValueError", Msg: "schema allows no values for wildcard", Pos: pos}
+	}
+	return errSchemaType(pos, "wildcard", schemas)
+}
+
+// errSchemaType returns an error signaling that the step cannot be taken on
+// values of the types of the schemas.
+func errSchemaType(pos int, step string, schemas []interface{}) ResolveError {
+	types := schemaTypes(schemas)
+	msg := fmt.Sprintf("cannot resolve %s on schema", step)
+	if len(types) != 0 {
+		msg = fmt.Sprintf("cannot resolve %s on schema of type %s", step, strings.Join(types, "|"))
+	} else if len(schemas) == 0 {
+		msg = fmt.Sprintf("cannot resolve %s on schema allowing no value", step)
+	}
+	return ResolveError{Code: "TypeError", Msg: msg, Pos: pos}
+}
+
+// errSchema returns an error signaling that the schema is invalid.
+func errSchema(pos int, format string, args ...interface{}) ResolveError {
+	return ResolveError{Code: "ValueError", Msg: fmt.Sprintf(format, args...), Pos: pos}
+}
+
+// schemaTypes returns the sorted types the schemas allow, or nil if any
+// type is allowed. Schemas without a `type` allow objects if they declare
+// properties and arrays if they declare items.
+func schemaTypes(schemas []interface{}) []string {
+	seen := map[string]bool{}
+	for _, schema := range schemas {
+		m, ok := schema.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+
+		switch t := m["type"].(type) {
+		case string:
+			seen[t] = true
+		case []interface{}:
+			for _, t := range t {
+				if s, ok := t.(string); ok {
+					seen[s] = true
+				}
+			}
+		default:
+			inferred := false
+			for _, keyword := range []string{"properties", "patternProperties", "additionalProperties"} {
+				if _, ok := m[keyword]; ok {
+					seen["object"], inferred = true, true
+				}
+			}
+			for _, keyword := range []string{"items", "prefixItems", "additionalItems"} {
+				if _, ok := m[keyword]; ok {
+					seen["array"], inferred = true, true
+				}
+			}
+			if !inferred {
+				return nil
+			}
+		}
+	}
+
+	types := make([]string, 0, len(seen))
+	for t := range seen {
+		types = append(types, t)
+	}
+	sort.Strings(types)
+	return types
+}
+
+// allowsType determines if the type is among the types, or if any type is
+// allowed.
+func allowsType(types []string, t string) bool {
+	if types == nil {
+		return true
+	}
+	for _, allowed := range types {
+		if allowed == t {
+			return true
+		}
+	}
+	return false
+}
+
+// sortedSchemaKeys returns the keys of the schema map, sorted.
+func sortedSchemaKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,314 @@
+package selectr
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+const testSchema = `{
+	"type": "object",
+	"properties": {
+		"server": {
+			"type": "object",
+			"properties": {
+				"host": {"type": "string"},
+				"port": {"type": "integer"},
+				"tls": {"$ref": "#/$defs/tls"}
+			},
+			"additionalProperties": false
+		},
+		"users": {
+			"type": "array",
+			"items": {"$ref": "#/$defs/user"}
+		},
+		"pair": {
+			"type": "array",
+			"prefixItems": [{"type": "string"}, {"type": "number"}],
+			"items": false
+		},
+		"labels": {
+			"type": "object",
+			"additionalProperties": {"type": "string"}
+		},
+		"env": {
+			"type": "object",
+			"patternProperties": {"^[A-Z_]+$": {"type": "string"}},
+			"additionalProperties": false
+		},
+		"auth": {
+			"oneOf": [
+				{"type": "object", "properties": {"token": {"type": "string"}}, "additionalProperties": false},
+				{"type": "object", "properties": {"user": {"type": "string"}, "password": {"type": "string"}}, "additionalProperties": false}
+			]
+		},
+		"timeout": {"anyOf": [{"type": "integer"}, {"type": "string"}, {"type": "null"}]},
+		"extra": {}
+	},
+	"additionalProperties": false,
+	"$defs": {
+		"tls": {
+			"type": "object",
+			"properties": {"cert": {"type": "string"}, "key": {"type": "string"}},
+			"additionalProperties": false
+		},
+		"user": {
+			"type": "object",
+			"properties": {
+				"name": {"type": "string"},
+				"manager": {"$ref": "#/$defs/user"}
+			}
+		}
+	}
+}`
+
+func newTestSchema(t *testing.T) interface{} {
+	t.Helper()
+
+	var schema interface{}
+	if err := json.Unmarshal([]byte(testSchema), &schema); err != nil {
+		t.Fatal(err)
+	}
+	return schema
+}
+
+type checkSelectorTestFixture struct {
+	schema   interface{}
+	selector string
+	expected []string
+	err      error
+}
+
+func runCheckSelectorTest(t *testing.T, fixture checkSelectorTestFixture) {
+	t.Helper()
+
+	sel, err := Parse(fixture.selector)
+	if err != nil {
+		t.Fatalf("`%s` could not be parsed: %s", fixture.selector, err)
+	}
+
+	types, err := CheckSelector(fixture.schema, sel)
+	if diff := cmp.Diff(fixture.err, err); diff != "" {
+		t.Errorf("unexpected error checking `%s`:\n%s", fixture.selector, diff)
+	}
+	if diff := cmp.Diff(fixture.expected, types); diff != "" {
+		t.Errorf("unexpected types of `%s`:\n%s", fixture.selector, diff)
+	}
+}
+
+func TestCheckSelector(t *testing.T) {
+	schema := newTestSchema(t)
+
+	for selector, expected := range map[string][]string{
+		"":                           {"object"},
+		".server":                    {"object"},
+		".server.port":               {"integer"},
+		".server.tls.cert":           {"string"},
+		".users":                     {"array"},
+		".users[3].name":             {"string"},
+		".users[*].manager.manager":  {"object"},
+		".pair[0]":                   {"string"},
+		".pair[1]":                   {"number"},
+		".labels['app.kubernetes']":  {"string"},
+		".labels.*":                  {"string"},
+		".env.HOME_DIR":              {"string"},
+		".auth.token":                {"string"},
+		".auth.password":             {"string"},
+		".timeout":                   {"integer", "null", "string"},
+		".extra":                     nil,
+		".extra.anything[0].at.all":  nil,
+		".*":                         nil,
+		".server.*":                  {"integer", "object", "string"},
+		".users[*].manager['name']":  {"string"},
+		".users[*].manager.manager2": nil,
+	} {
+		runCheckSelectorTest(t, checkSelectorTestFixture{schema: schema, selector: selector, expected: expected})
+	}
+}
+
+func TestCheckSelector_errors(t *testing.T) {
+	schema := newTestSchema(t)
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".server.prot",
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'prot' not found in '.server'; did you mean 'port'?",
+			Pos:         7,
+			Path:        ".server",
+			Suggestions: []string{"port"},
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: "server.tls.ca",
+		err: ResolveError{
+			Code: "KeyError",
+			Msg:  "key 'ca' not found in '.server.tls'",
+			Pos:  10,
+			Path: ".server.tls",
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".server.port.value",
+		err: ResolveError{
+			Code: "TypeError",
+			Msg:  "cannot resolve attribute 'value' on schema of type integer",
+			Pos:  12,
+			Path: ".server.port",
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".users.name",
+		err: ResolveError{
+			Code: "TypeError",
+			Msg:  "cannot resolve attribute 'name' on schema of type array",
+			Pos:  6,
+			Path: ".users",
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".server[0]",
+		err: ResolveError{
+			Code: "TypeError",
+			Msg:  "cannot resolve element '0' on schema of type object",
+			Pos:  7,
+			Path: ".server",
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".pair[2]",
+		err: ResolveError{
+			Code: "IndexError",
+			Msg:  "index out of range; schema allows at most 2 items",
+			Pos:  5,
+			Path: ".pair",
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".env.home",
+		err: ResolveError{
+			Code: "KeyError",
+			Msg:  "key 'home' not found in '.env'",
+			Pos:  4,
+			Path: ".env",
+		},
+	})
+
+	// keys allowed by none of the alternatives.
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".auth.tokn",
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'tokn' not found in '.auth'; did you mean 'token'?",
+			Pos:         5,
+			Path:        ".auth",
+			Suggestions: []string{"token"},
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   schema,
+		selector: ".timeout.unit",
+		err: ResolveError{
+			Code: "TypeError",
+			Msg:  "cannot resolve attribute 'unit' on schema of type integer|null|string",
+			Pos:  8,
+			Path: ".timeout",
+		},
+	})
+}
+
+func TestCheckSelector_invalidSchema(t *testing.T) {
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema: map[string]interface{}{
+			"properties": map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "#/$defs/missing"},
+			},
+		},
+		selector: ".a",
+		err: ResolveError{
+			Code: "ValueError",
+			Msg:  "cannot resolve $ref '#/$defs/missing': key '$defs' not found",
+			Pos:  0,
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema: map[string]interface{}{
+			"$defs": map[string]interface{}{
+				"a": map[string]interface{}{"$ref": "#/$defs/b"},
+				"b": map[string]interface{}{"$ref": "#/$defs/a"},
+			},
+			"$ref": "#/$defs/a",
+		},
+		selector: ".x",
+		err: ResolveError{
+			Code: "ValueError",
+			Msg:  "circular $ref '#/$defs/a'",
+			Pos:  0,
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   map[string]interface{}{"$ref": "https://example.com/schema.json"},
+		selector: ".x",
+		err: ResolveError{
+			Code: "ValueError",
+			Msg:  "cannot resolve $ref 'https://example.com/schema.json': only local references are supported",
+			Pos:  0,
+		},
+	})
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   false,
+		selector: ".x",
+		err: ResolveError{
+			Code: "TypeError",
+			Msg:  "cannot resolve attribute 'x' on schema allowing no value",
+			Pos:  0,
+		},
+	})
+}
+
+func TestCheckSelector_yaml(t *testing.T) {
+	var schema yaml.Node
+	src := "type: object\nproperties:\n  replicas: {type: integer}\n"
+	if err := yaml.Unmarshal([]byte(src), &schema); err != nil {
+		t.Fatal(err)
+	}
+
+	runCheckSelectorTest(t, checkSelectorTestFixture{
+		schema:   &schema,
+		selector: ".replicas",
+		expected: []string{"integer"},
+	})
+}
+
+func TestCheckSelector_diagnose(t *testing.T) {
+	selector := "server.tls.kye"
+	sel, _ := Parse(selector)
+	_, err := CheckSelector(newTestSchema(t), sel)
+
+	expected := "server.tls.kye\n" +
+		"          ^\n" +
+		"KeyError: key 'kye' not found in '.server.tls'; did you mean 'key'?"
+	if diff := cmp.Diff(expected, Diagnose(selector, err)); diff != "" {
+		t.Errorf("unexpected diagnostic:\n%s", diff)
+	}
+}
@@ -2,10 +2,14 @@ package selectr
 
 import (
 	"fmt"
+	"reflect"
+	"strconv"
 	"strings"
 
 	"github.com/0xch4z/selectr/internal/parser"
 	"github.com/0xch4z/selectr/internal/parser/ast"
+	"github.com/0xch4z/selectr/internal/parser/token"
+	"gopkg.in/yaml.v3"
 )
 
 // ResolveError represents an error that occured while resolving a
@@ -17,6 +21,14 @@ type ResolveError struct {
 	// Pos is the position in the corresponding key-path of the underlying
 	// expression that the resolver was dervied from.
 	Pos int
+
+	// Path is the canonical key-path that resolved successfully before the
+	// error occurred.
+	Path string
+
+	// Suggestions holds the closest existing keys when a key was not found
+	// (see Strict).
+	Suggestions []string
 }
 
 // Error implements (error).Error
@@ -27,6 +39,21 @@ func (err ResolveError) Error() string {
 	return err.Msg
 }
 
+// AmbiguousKeyError is returned when a key matches several keys of a map
+// or fields of a struct under case-insensitive or normalized matching (see
+// CaseInsensitive and NormalizeKeys).
+type AmbiguousKeyError struct {
+	ResolveError
+
+	// Matches holds the keys that matched, sorted.
+	Matches []string
+}
+
+// Unwrap returns the underlying ResolveError.
+func (err AmbiguousKeyError) Unwrap() error {
+	return err.ResolveError
+}
+
 // Resolver resolves a value from an object.
 type Resolver interface {
 	Resolve(interface{}) (interface{}, error)
@@ -40,15 +67,140 @@ type MapEntryResolver struct {
 }
 
 // Resolve resolves the value of an entry on the map.
+//
+// Maps keyed by `interface{}` (as decoded by gopkg.in/yaml.v2) match
+// string, integer and boolean keys by their string representation. yaml.v3
+// mapping nodes resolve to the value node of the entry. Other maps keyed by
+// strings and structs are resolved by reflection; struct fields are
+// selected by their `json` name or their Go name.
 func (r *MapEntryResolver) Resolve(v interface{}) (interface{}, error) {
+	return r.resolve(v, &resolveOptions{})
+}
+
+// resolve resolves the value of an entry on the map with the options.
+func (r *MapEntryResolver) resolve(v interface{}, o *resolveOptions) (interface{}, error) {
+	var (
+		val   interface{}
+		found bool
+	)
+
 	switch v := v.(type) {
 	case map[string]interface{}:
-		return v[r.Key], nil
+		val, found = v[r.Key]
+
+	case map[interface{}]interface{}:
+		if val, found = v[r.Key]; !found {
+			for k, kv := range v {
+				if interfaceKeyMatches(k, r.Key) {
+					val, found = kv, true
+					break
+				}
+			}
+		}
+
+	case *yaml.Node:
+		n := derefYAMLNode(v)
+		if n == nil || n.Kind != yaml.MappingNode {
+			return nil, ResolveError{
+				Code: "TypeError",
+				Msg:  fmt.Sprintf("cannot resolve attribute '%s' on %s", r.Key, yamlKindName(n)),
+				Pos:  r.Expr.StartPos(),
+			}
+		}
+		var node *yaml.Node
+		if node, found = yamlMappingLookup(n, yamlKeyMatcher(r.Key)); found {
+			val = node
+		}
+
+	default:
+		var ok bool
+		if val, found, ok = r.resolveReflect(v); !ok {
+			return nil, ResolveError{
+				Code: "TypeError",
+				Msg:  fmt.Sprintf("cannot resolve attribute '%s' on type %T", r.Key, v),
+				Pos:  r.Expr.StartPos(),
+			}
+		}
 	}
-	return nil, ResolveError{
-		Code: "TypeError",
-		Msg:  fmt.Sprintf("cannot resolve attribute '%s' on type %T", r.Key, v),
-		Pos:  r.Expr.StartPos(),
+
+	if !found && o.foldKey != nil {
+		var err error
+		if val, found, err = r.resolveFolded(v, o); err != nil {
+			return nil, err
+		}
+	}
+
+	if !found && o.strict {
+		return nil, o.errKeyNotFound(r.Key, r.Expr.StartPos(), v)
+	}
+	return val, nil
+}
+
+// resolveReflect resolves the value of an entry on a map keyed by strings
+// or the value of a field on a struct. Struct fields are selected by the
+// name in their `json` tag or their Go name. ok is false if the value is
+// not a map keyed by strings or a struct.
+func (r *MapEntryResolver) resolveReflect(v interface{}) (val interface{}, found, ok bool) {
+	rv := indirect(reflect.ValueOf(v))
+
+	switch rv.Kind() {
+	case reflect.Map:
+		kt := rv.Type().Key()
+		if kt.Kind() != reflect.String {
+			return nil, false, false
+		}
+		if mv := rv.MapIndex(reflect.ValueOf(r.Key).Convert(kt)); mv.IsValid() {
+			return mv.Interface(), true, true
+		}
+		return nil, false, true
+
+	case reflect.Struct:
+		for _, f := range structFields(rv.Type()) {
+			if f.name != r.Key && f.goName != r.Key {
+				continue
+			}
+			if fv, ok := fieldByIndex(rv, f.index); ok {
+				return fv.Interface(), true, true
+			}
+			return nil, true, true
+		}
+		return nil, false, true
+	}
+
+	return nil, false, false
+}
+
+// resolveFolded resolves the value of the entry whose key matches the key
+// once both are folded by the options (see CaseInsensitive and
+// NormalizeKeys). An AmbiguousKeyError is returned if several keys match.
+func (r *MapEntryResolver) resolveFolded(v interface{}, o *resolveOptions) (interface{}, bool, error) {
+	folded := o.foldKey(r.Key)
+
+	var matches []entry
+	for _, e := range entries(v) {
+		if k, ok := e.key.(string); ok && o.foldKey(k) == folded {
+			matches = append(matches, e)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, false, nil
+	case 1:
+		return matches[0].val, true, nil
+	}
+
+	var keys []string
+	for _, e := range matches {
+		keys = append(keys, e.key.(string))
+	}
+	return nil, false, AmbiguousKeyError{
+		ResolveError: ResolveError{
+			Code: "AmbiguousKeyError",
+			Msg:  fmt.Sprintf("key '%s' matches several keys: '%s'", r.Key, strings.Join(keys, "', '")),
+			Pos:  r.Expr.StartPos(),
+		},
+		Matches: keys,
 	}
 }
 
@@ -68,12 +220,63 @@ type SliceElementResolver struct {
 
 // Resolve resolves the value of the element at the index on the slice.
 func (r *SliceElementResolver) Resolve(v interface{}) (interface{}, error) {
+	return r.resolve(v, &resolveOptions{})
+}
+
+// resolve resolves the value of the element at the index on the slice
+// with the options.
+func (r *SliceElementResolver) resolve(v interface{}, o *resolveOptions) (interface{}, error) {
 	switch v := v.(type) {
 	case []interface{}:
 		if r.Index > len(v)-1 {
-			return nil, fmt.Errorf("index out of range; index is %d but length is only %d", r.Index, len(v))
+			return nil, r.errOutOfRange(len(v))
 		}
 		return v[r.Index], nil
+
+	case map[interface{}]interface{}:
+		// maps decoded from YAML may be keyed by integers.
+		for k, val := range v {
+			if n, ok := intKey(k); ok && n == int64(r.Index) {
+				return val, nil
+			}
+		}
+		if o.strict {
+			return nil, o.errKeyNotFound(r.Index, r.Expr.StartPos(), v)
+		}
+		return nil, nil
+
+	case *yaml.Node:
+		n := derefYAMLNode(v)
+		if n != nil {
+			switch n.Kind {
+			case yaml.SequenceNode:
+				if r.Index > len(n.Content)-1 {
+					return nil, r.errOutOfRange(len(n.Content))
+				}
+				return derefYAMLNode(n.Content[r.Index]), nil
+
+			case yaml.MappingNode:
+				if val, ok := yamlMappingLookup(n, yamlIntKeyMatcher(r.Index)); ok {
+					return val, nil
+				}
+				if o.strict {
+					return nil, o.errKeyNotFound(r.Index, r.Expr.StartPos(), v)
+				}
+				return nil, nil
+			}
+		}
+		return nil, ResolveError{
+			Code: "TypeError",
+			Msg:  fmt.Sprintf("cannot resolve element '%d' on %s", r.Index, yamlKindName(n)),
+			Pos:  r.Expr.StartPos(),
+		}
+	}
+
+	if rv := indirect(reflect.ValueOf(v)); rv.Kind() == reflect.Slice || rv.Kind() == reflect.Array {
+		if r.Index > rv.Len()-1 {
+			return nil, r.errOutOfRange(rv.Len())
+		}
+		return rv.Index(r.Index).Interface(), nil
 	}
 	return nil, ResolveError{
 		Code: "TypeError",
@@ -82,6 +285,16 @@ func (r *SliceElementResolver) Resolve(v interface{}) (interface{}, error) {
 	}
 }
 
+// errOutOfRange returns an error signaling that the index is out of range
+// for a slice of the given length.
+func (r *SliceElementResolver) errOutOfRange(length int) error {
+	return ResolveError{
+		Code: "IndexError",
+		Msg:  fmt.Sprintf("index out of range; index is %d but length is only %d", r.Index, length),
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
 // Expression returns the corresponding ast.Expr.
 func (r *SliceElementResolver) Expression() ast.Expr {
 	return r.Expr
@@ -90,6 +303,48 @@ func (r *SliceElementResolver) Expression() ast.Expr {
 // SliceElementResolver implements Resolver.
 var _ Resolver = (*SliceElementResolver)(nil)
 
+// interfaceKeyMatches determines if the key of an interface keyed map
+// matches the key. String, integer and boolean keys are supported.
+func interfaceKeyMatches(k interface{}, key string) bool {
+	switch k := k.(type) {
+	case string:
+		return k == key
+	case bool:
+		return strconv.FormatBool(k) == key
+	}
+	if n, ok := intKey(k); ok {
+		return strconv.FormatInt(n, 10) == key
+	}
+	return false
+}
+
+// intKey returns the value of an integer map key.
+func intKey(k interface{}) (int64, bool) {
+	switch k := k.(type) {
+	case int:
+		return int64(k), true
+	case int8:
+		return int64(k), true
+	case int16:
+		return int64(k), true
+	case int32:
+		return int64(k), true
+	case int64:
+		return k, true
+	case uint:
+		return int64(k), true
+	case uint8:
+		return int64(k), true
+	case uint16:
+		return int64(k), true
+	case uint32:
+		return int64(k), true
+	case uint64:
+		return int64(k), true
+	}
+	return 0, false
+}
+
 // Parse parses a traversal tree from the selector string and returns
 // a new Selector instance.
 func Parse(s string) (*Selector, error) {
@@ -104,6 +359,10 @@ func Parse(s string) (*Selector, error) {
 
 		switch e := expr.(type) {
 		case *ast.AttrExpr:
+			if e.Attr.Tok == token.Star {
+				resolver = &WildcardResolver{Expr: e}
+				break
+			}
 			resolver = &MapEntryResolver{
 				Key:  e.Attr.Lit,
 				Expr: e,
@@ -122,6 +381,9 @@ func Parse(s string) (*Selector, error) {
 					Key:  indexExpr.Value().(string),
 					Expr: e,
 				}
+
+			case *ast.WildcardLit:
+				resolver = &WildcardResolver{Expr: e}
 			}
 		}
 
@@ -155,30 +417,118 @@ type TraversalTreeNode struct {
 // Selector represents a value selection on an object.
 type Selector struct {
 	tree *TraversalTreeNode
+
+	// err is the error recorded while building the selector (see Root).
+	err error
 }
 
 // Resolve resolves the value at the specified key-path, if any, from the
 // provided object. The root object must be an indexable type such as a
-// Map `map[string]interface{}` or a Slice `[]interface{}`.
+// Map `map[string]interface{}` or `map[interface{}]interface{}`, a Slice
+// `[]interface{}` or a yaml.v3 `*yaml.Node`. Other maps keyed by strings,
+// slices, arrays and structs are resolved by reflection.
 //
-// All errors will be prefixed with the sub-key-path the error occured at.
+// Keys must match exactly unless the CaseInsensitive or NormalizeKeys
+// option is given. Missing map keys resolve to nil unless the Strict
+// option is given. All ResolveErrors carry the key-path that resolved
+// successfully before the error occurred.
+//
+// If the object is a *Tracker, the value is resolved on the document it
+// wraps and its path is recorded, as by (*Tracker).Resolve.
 //
 // Example usage:
 //
 //	    sel := Parse("test[0].foo")
 //	    sel.Resolve(map[string]interface{}{
-//		       "test": []map[string]interface{}{
-//			       {"foo": "bar"}
+//		       "test": []interface{}{
+//			       map[string]interface{}{"foo": "bar"}
 //	        }
 //	    })
-func (s *Selector) Resolve(v interface{}) (interface{}, error) {
+func (s *Selector) Resolve(v interface{}, opts ...ResolveOption) (interface{}, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	if t, ok := v.(*Tracker); ok {
+		return t.Resolve(s, opts...)
+	}
+	o := newResolveOptions(opts)
+
 	curr := s.tree
 	for curr != nil {
 		var err error
-		if v, err = curr.Resolver.Resolve(v); err != nil {
+		if r, ok := curr.Resolver.(optionResolver); ok {
+			v, err = r.resolve(v, o)
+		} else {
+			v, err = curr.Resolver.Resolve(v)
+		}
+		if err != nil {
+			switch e := err.(type) {
+			case ResolveError:
+				e.Path = pathString(s.tree, curr)
+				if e.Code == "KeyError" {
+					e.Msg = keyNotFoundMsg(stepKey(curr.Resolver), e.Path, e.Suggestions)
+				}
+				err = e
+			case AmbiguousKeyError:
+				e.Path = pathString(s.tree, curr)
+				err = e
+			}
 			return nil, err
 		}
 		curr = curr.Child
 	}
 	return v, nil
 }
+
+// String returns the canonical key-path of the selector. Keys that are
+// identifiers are written as attribute expressions, all other keys and
+// indices as index expressions.
+func (s *Selector) String() string {
+	return pathString(s.tree, nil)
+}
+
+// stepKey returns the key or index the resolver selects.
+func stepKey(r Resolver) interface{} {
+	switch r := r.(type) {
+	case *MapEntryResolver:
+		return r.Key
+	case *SliceElementResolver:
+		return r.Index
+	case *WildcardResolver:
+		return wildcard{}
+	}
+	return nil
+}
+
+// keys returns the keys and indices the selector selects, in order.
+func (s *Selector) keys() []interface{} {
+	var keys []interface{}
+	for curr := s.tree; curr != nil; curr = curr.Child {
+		keys = append(keys, stepKey(curr.Resolver))
+	}
+	return keys
+}
+
+// segments returns the canonical segments of the selector.
+func (s *Selector) segments() []string {
+	return keySegments(s.keys())
+}
+
+// keySegments returns the canonical segments of the keys and indices.
+func keySegments(keys []interface{}) []string {
+	var segments []string
+	for _, key := range keys {
+		segments = append(segments, segmentFor(key))
+	}
+	return segments
+}
+
+// pathString returns the canonical key-path of the traversal tree from
+// head up to, but excluding, end.
+func pathString(head, end *TraversalTreeNode) string {
+	var b strings.Builder
+	for curr := head; curr != nil && curr != end; curr = curr.Child {
+		b.WriteString(segmentFor(stepKey(curr.Resolver)))
+	}
+	return b.String()
+}
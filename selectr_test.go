@@ -28,6 +28,7 @@ type resolveTestFixture struct {
 	err      error
 	errRegex *regexp.Regexp
 	val      interface{}
+	opts     []ResolveOption
 	expected interface{}
 }
 
@@ -70,7 +71,7 @@ func runResolveTest(t *testing.T, fixture resolveTestFixture) {
 		return
 	}
 
-	result, resolveErr := sel.Resolve(fixture.val)
+	result, resolveErr := sel.Resolve(fixture.val, fixture.opts...)
 
 	if fixture.errRegex != nil {
 		if resolveErr == nil {
@@ -172,6 +173,15 @@ func TestParse(t *testing.T) {
 		}),
 	})
 
+	runParseTest(t, parseTestFixture{
+		selector: `['a\0b\e']`,
+		expected: treeFromResolverSequence([]Resolver{
+			&MapEntryResolver{
+				Key: "a\x00b\x1b",
+			},
+		}),
+	})
+
 	runParseTest(t, parseTestFixture{
 		selector: "[40]",
 		expected: treeFromResolverSequence([]Resolver{
@@ -245,6 +255,7 @@ func TestResolve_error(t *testing.T) {
 			Code: "TypeError",
 			Msg:  "cannot resolve element '0' on type <nil>",
 			Pos:  4,
+			Path: ".arr",
 		},
 	})
 
@@ -264,3 +275,278 @@ func TestResolve_error(t *testing.T) {
 		errRegex: regexp.MustCompile("index out of range; index is 5 but length is only 3"),
 	})
 }
+
+func TestResolve_interfaceKeyedMap(t *testing.T) {
+	// maps decoded by gopkg.in/yaml.v2 are keyed by interface{}.
+	val := map[interface{}]interface{}{
+		"accounts": []interface{}{
+			map[interface{}]interface{}{"name": "main"},
+		},
+		1:    "one",
+		true: "yes",
+	}
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts[0].name",
+		val:      val,
+		expected: "main",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: "['1']",
+		val:      val,
+		expected: "one",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: "[1]",
+		val:      val,
+		expected: "one",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: "['true']",
+		val:      val,
+		expected: "yes",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".missing",
+		val:      val,
+		expected: nil,
+	})
+}
+
+func TestResolve_strict(t *testing.T) {
+	val := map[string]interface{}{
+		"accounts": []interface{}{
+			map[string]interface{}{"name": "main", "Names": []interface{}{}},
+		},
+		"settings": map[string]interface{}{"debug": nil},
+	}
+
+	runStrictResolveTest := func(fixture resolveTestFixture) {
+		t.Helper()
+
+		sel, _ := Parse(fixture.selector)
+		result, err := sel.Resolve(fixture.val, Strict())
+		if diff := cmp.Diff(fixture.err, err); diff != "" {
+			t.Errorf("error for resolving `%s` was not as expected:\n%s", fixture.selector, diff)
+		}
+		if diff := cmp.Diff(fixture.expected, result); diff != "" {
+			t.Errorf("`%s` was not resolved as expected:\n%s", fixture.selector, diff)
+		}
+	}
+
+	runStrictResolveTest(resolveTestFixture{
+		selector: ".acounts[0].name",
+		val:      val,
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'acounts' not found; did you mean 'accounts'?",
+			Pos:         0,
+			Suggestions: []string{"accounts"},
+		},
+	})
+
+	// case-insensitive matches rank first, and the path resolved before
+	// the failure is included.
+	runStrictResolveTest(resolveTestFixture{
+		selector: ".accounts[0].names",
+		val:      val,
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'names' not found in '.accounts[0]'; did you mean one of 'Names', 'name'?",
+			Pos:         12,
+			Path:        ".accounts[0]",
+			Suggestions: []string{"Names", "name"},
+		},
+	})
+
+	runStrictResolveTest(resolveTestFixture{
+		selector: ".settings.verbose",
+		val:      val,
+		err: ResolveError{
+			Code: "KeyError",
+			Msg:  "key 'verbose' not found in '.settings'",
+			Pos:  9,
+			Path: ".settings",
+		},
+	})
+
+	// existing keys holding nil resolve without an error.
+	runStrictResolveTest(resolveTestFixture{
+		selector: ".settings.debug",
+		val:      val,
+		expected: nil,
+	})
+}
+
+func TestResolve_caseInsensitive(t *testing.T) {
+	val := map[string]interface{}{
+		"Accounts": []interface{}{
+			map[string]interface{}{"Name": "main", "name": "exact"},
+		},
+		"Settings": map[string]interface{}{"Debug": true, "DEBUG": false},
+	}
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts[0].NAME",
+		val:      val,
+		opts:     []ResolveOption{CaseInsensitive()},
+		err: AmbiguousKeyError{
+			ResolveError: ResolveError{
+				Code: "AmbiguousKeyError",
+				Msg:  "key 'NAME' matches several keys: 'Name', 'name'",
+				Pos:  12,
+				Path: ".accounts[0]",
+			},
+			Matches: []string{"Name", "name"},
+		},
+	})
+
+	// exact matches take precedence over case-insensitive ones.
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts[0].name",
+		val:      val,
+		opts:     []ResolveOption{CaseInsensitive()},
+		expected: "exact",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".settings.DEBUG",
+		val:      val,
+		opts:     []ResolveOption{CaseInsensitive()},
+		expected: false,
+	})
+
+	// keys must match exactly by default.
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts",
+		val:      val,
+		expected: nil,
+	})
+
+	// missing keys are still reported in strict mode.
+	runResolveTest(t, resolveTestFixture{
+		selector: ".setting",
+		val:      val,
+		opts:     []ResolveOption{CaseInsensitive(), Strict()},
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'setting' not found; did you mean 'Settings'?",
+			Pos:         0,
+			Suggestions: []string{"Settings"},
+		},
+	})
+}
+
+func TestResolve_normalizeKeys(t *testing.T) {
+	val := map[interface{}]interface{}{
+		"content_type": "application/json",
+		"maxRetries":   3,
+		"Time-Out":     "5s",
+	}
+
+	for selector, expected := range map[string]interface{}{
+		".contentType":      "application/json",
+		"['content-type']":  "application/json",
+		".ContentType":      "application/json",
+		".max_retries":      3,
+		"['MAX-RETRIES']":   3,
+		".timeout":          "5s",
+		".time_out":         "5s",
+		".missing_selector": nil,
+	} {
+		runResolveTest(t, resolveTestFixture{
+			selector: selector,
+			val:      val,
+			opts:     []ResolveOption{NormalizeKeys()},
+			expected: expected,
+		})
+	}
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".user_id",
+		val:      map[string]interface{}{"userId": 1, "user-id": 2},
+		opts:     []ResolveOption{NormalizeKeys()},
+		err: AmbiguousKeyError{
+			ResolveError: ResolveError{
+				Code: "AmbiguousKeyError",
+				Msg:  "key 'user_id' matches several keys: 'user-id', 'userId'",
+				Pos:  0,
+			},
+			Matches: []string{"user-id", "userId"},
+		},
+	})
+}
+
+func TestResolve_struct(t *testing.T) {
+	type Base struct {
+		ID int `json:"id"`
+	}
+	type Account struct {
+		Base
+		Name        string `json:"name"`
+		ContentType string
+		Secret      string `json:"-"`
+		Tags        []string
+	}
+
+	val := map[string]interface{}{
+		"accounts": []Account{{Base: Base{ID: 123}, Name: "main", ContentType: "json", Tags: []string{"a", "b"}}},
+	}
+
+	for selector, expected := range map[string]interface{}{
+		".accounts[0].id":          123,
+		".accounts[0].name":        "main",
+		".accounts[0].Name":        "main",
+		".accounts[0].ContentType": "json",
+		".accounts[0].Tags[1]":     "b",
+		".accounts[0].Secret":      nil,
+		".accounts[0].missing":     nil,
+	} {
+		runResolveTest(t, resolveTestFixture{
+			selector: selector,
+			val:      val,
+			expected: expected,
+		})
+	}
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts[0].content_type",
+		val:      val,
+		opts:     []ResolveOption{NormalizeKeys()},
+		expected: "json",
+	})
+
+	runResolveTest(t, resolveTestFixture{
+		selector: ".accounts[1]",
+		val:      val,
+		err: ResolveError{
+			Code: "IndexError",
+			Msg:  "index out of range; index is 1 but length is only 1",
+			Pos:  9,
+			Path: ".accounts",
+		},
+	})
+}
+
+func TestSelectorString(t *testing.T) {
+	for selector, expected := range map[string]string{
+		"":                       "",
+		"foo":                    ".foo",
+		`["foo"]['bar'][0]`:      ".foo.bar[0]",
+		`['content-type']`:       "['content-type']",
+		`.a['it\'s'][1]["x\ny"]`: `.a['it\'s'][1]['x\ny']`,
+	} {
+		sel, err := Parse(selector)
+		if err != nil {
+			t.Errorf("could not parse selector `%s`: %s", selector, err)
+			continue
+		}
+		if s := sel.String(); s != expected {
+			t.Errorf("expected `%s` to be formatted as `%s` but got `%s`", selector, expected, s)
+		}
+	}
+}
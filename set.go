@@ -0,0 +1,236 @@
+package selectr
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// SelectorSet resolves many selectors at once. The selectors are compiled
+// into a trie of their steps, so selectors sharing a prefix, such as
+// `.event.user.id` and `.event.user.name`, resolve it only once and each
+// value of a document is traversed at most once.
+type SelectorSet struct {
+	selectors []*Selector
+	root      *setNode
+}
+
+// setNode represents a step of the selectors of a SelectorSet.
+type setNode struct {
+	resolver Resolver
+
+	// selectors are the indices of the selectors ending at the step.
+	selectors []int
+
+	// children are the next steps keyed by their canonical segment, in
+	// the order they were added.
+	children map[string]*setNode
+	order    []*setNode
+}
+
+// child returns the next step of the segment, adding it if needed.
+func (n *setNode) child(segment string, r Resolver) *setNode {
+	if c, ok := n.children[segment]; ok {
+		return c
+	}
+	if n.children == nil {
+		n.children = map[string]*setNode{}
+	}
+
+	c := &setNode{resolver: r}
+	n.children[segment] = c
+	n.order = append(n.order, c)
+	return c
+}
+
+// NewSelectorSet compiles the selectors into a SelectorSet. An error is
+// returned if a selector contains a wildcard, as a wildcard does not
+// resolve to a single value.
+func NewSelectorSet(selectors ...*Selector) (*SelectorSet, error) {
+	s := &SelectorSet{selectors: selectors, root: &setNode{}}
+	for i, sel := range selectors {
+		n := s.root
+		for curr := sel.tree; curr != nil; curr = curr.Child {
+			if _, ok := curr.Resolver.(*WildcardResolver); ok {
+				return nil, fmt.Errorf("cannot add '%s' to a selector set: wildcards match several values", sel)
+			}
+			n = n.child(segmentFor(stepKey(curr.Resolver)), curr.Resolver)
+		}
+		n.selectors = append(n.selectors, i)
+	}
+	return s, nil
+}
+
+// ResolveAll resolves the selectors of the set on the document in a single
+// pass and returns their values keyed by selector. Keys and indices are
+// matched like Resolve, with the same options.
+//
+// Only the selectors of values that exist on the document are in the
+// returned map, so a missing key can be told apart from a null value. With
+// the Strict option, the error Resolve would return for the first selector
+// that could not be resolved, in the order the selectors were given, is
+// returned instead.
+//
+// Example usage:
+//
+//	id, _ := Parse(".user.id")
+//	name, _ := Parse(".user.name")
+//	set, _ := NewSelectorSet(id, name)
+//
+//	values, _ := set.ResolveAll(doc)
+//	values[id] // => 123
+func (s *SelectorSet) ResolveAll(doc interface{}, opts ...ResolveOption) (map[*Selector]interface{}, error) {
+	// missing keys are detected in strict mode, but only reported by
+	// resolving their selectors again if the Strict option is given.
+	o := newResolveOptions(opts)
+	strict := o.strict
+	o.strict = true
+	o.skipSuggestions = true
+
+	values := make(map[*Selector]interface{}, len(s.selectors))
+	if err := s.resolveAll(s.root, doc, o, values); err != nil {
+		return nil, err
+	}
+
+	if strict && len(values) != len(s.selectors) {
+		for _, sel := range s.selectors {
+			if _, ok := values[sel]; !ok {
+				if _, err := sel.Resolve(doc, opts...); err != nil {
+					return nil, err
+				}
+			}
+		}
+	}
+	return values, nil
+}
+
+// resolveAll records the value of the selectors ending at the node and
+// resolves the next steps on it. Steps that cannot be resolved are skipped;
+// only errors other than those of missing keys, out of range indices and
+// mismatched types are returned.
+func (s *SelectorSet) resolveAll(n *setNode, v interface{}, o *resolveOptions, values map[*Selector]interface{}) error {
+	s.record(n, v, values)
+
+	for _, c := range n.order {
+		var child interface{}
+		var err error
+		if r, ok := c.resolver.(optionResolver); ok {
+			child, err = r.resolve(v, o)
+		} else {
+			child, err = c.resolver.Resolve(v)
+		}
+		if err != nil {
+			if e, ok := err.(ResolveError); ok && (e.Code == "KeyError" || e.Code == "IndexError" || e.Code == "TypeError") {
+				continue
+			}
+			return err
+		}
+
+		if err := s.resolveAll(c, child, o, values); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// record sets the value of the selectors ending at the node.
+func (s *SelectorSet) record(n *setNode, v interface{}, values map[*Selector]interface{}) {
+	for _, i := range n.selectors {
+		values[s.selectors[i]] = v
+	}
+}
+
+// ResolveJSON resolves the selectors of the set on the next JSON value read
+// from the decoder, like ResolveAll, without decoding the whole value:
+// only the values of the selectors are decoded, and everything else is
+// skipped token by token. Keys are matched exactly and values are decoded
+// as by the decoder into an interface{}, e.g. numbers as float64 unless
+// UseNumber was called.
+//
+// As the value is read from the decoder, consecutive JSON values, such as
+// a stream of events, are resolved by calling ResolveJSON repeatedly until
+// io.EOF is returned.
+func (s *SelectorSet) ResolveJSON(dec *json.Decoder) (map[*Selector]interface{}, error) {
+	values := make(map[*Selector]interface{}, len(s.selectors))
+	if err := s.resolveJSON(s.root, dec, values); err != nil {
+		return nil, err
+	}
+	return values, nil
+}
+
+// resolveJSON reads the next JSON value from the decoder, recording the
+// values of the selectors ending at the node and within it.
+func (s *SelectorSet) resolveJSON(n *setNode, dec *json.Decoder, values map[*Selector]interface{}) error {
+	if len(n.selectors) != 0 {
+		// the whole value is needed, so the steps within it are resolved
+		// on its decoded value.
+		var v interface{}
+		if err := dec.Decode(&v); err != nil {
+			return err
+		}
+		return s.resolveAll(n, v, &resolveOptions{strict: true, skipSuggestions: true}, values)
+	}
+
+	tok, err := dec.Token()
+	if err != nil {
+		return err
+	}
+
+	switch tok {
+	case json.Delim('{'):
+		for dec.More() {
+			tok, err := dec.Token()
+			if err != nil {
+				return err
+			}
+			if err := s.resolveJSONChild(n, segmentFor(tok), dec, values); err != nil {
+				return err
+			}
+		}
+
+	case json.Delim('['):
+		for i := 0; dec.More(); i++ {
+			if err := s.resolveJSONChild(n, segmentFor(i), dec, values); err != nil {
+				return err
+			}
+		}
+
+	default:
+		// steps cannot be resolved on scalars.
+		return nil
+	}
+
+	// read the closing delimiter.
+	_, err = dec.Token()
+	return err
+}
+
+// resolveJSONChild reads the next JSON value from the decoder, resolving
+// the step of the segment on it, or skipping it if there is none.
+func (s *SelectorSet) resolveJSONChild(n *setNode, segment string, dec *json.Decoder, values map[*Selector]interface{}) error {
+	if c, ok := n.children[segment]; ok {
+		return s.resolveJSON(c, dec, values)
+	}
+	return skipJSONValue(dec)
+}
+
+// skipJSONValue reads the next JSON value from the decoder without
+// decoding it.
+func skipJSONValue(dec *json.Decoder) error {
+	depth := 0
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			return err
+		}
+
+		switch tok {
+		case json.Delim('{'), json.Delim('['):
+			depth++
+		case json.Delim('}'), json.Delim(']'):
+			depth--
+		}
+		if depth == 0 {
+			return nil
+		}
+	}
+}
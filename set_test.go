@@ -0,0 +1,216 @@
+package selectr
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+type selectorSetTestFixture struct {
+	selectors []string
+	opts      []ResolveOption
+	val       interface{}
+	expected  map[string]interface{}
+	err       error
+}
+
+// valuesBySelector keys the values by the canonical selectors.
+func valuesBySelector(values map[*Selector]interface{}) map[string]interface{} {
+	if values == nil {
+		return nil
+	}
+
+	m := make(map[string]interface{}, len(values))
+	for sel, v := range values {
+		m[sel.String()] = v
+	}
+	return m
+}
+
+func runSelectorSetTest(t *testing.T, fixture selectorSetTestFixture) {
+	t.Helper()
+
+	set, err := NewSelectorSet(mustParseAll(t, fixture.selectors...)...)
+	if err != nil {
+		t.Fatalf("unexpected error compiling %v: %s", fixture.selectors, err)
+	}
+
+	values, err := set.ResolveAll(fixture.val, fixture.opts...)
+	if diff := cmp.Diff(fixture.err, err); diff != "" {
+		t.Errorf("unexpected error resolving %v:\n%s", fixture.selectors, diff)
+	}
+	if diff := cmp.Diff(fixture.expected, valuesBySelector(values)); diff != "" {
+		t.Errorf("%v were not resolved as expected:\n%s", fixture.selectors, diff)
+	}
+}
+
+func newEventDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"event": map[string]interface{}{
+			"type": "login",
+			"user": map[string]interface{}{"id": 123, "name": "ann", "email": nil},
+			"tags": []interface{}{"web", "eu"},
+		},
+	}
+}
+
+func TestSelectorSetResolveAll(t *testing.T) {
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".event.user.id", ".event.user.name", ".event.tags[1]", ".event.type"},
+		val:       newEventDoc(),
+		expected: map[string]interface{}{
+			".event.user.id":   123,
+			".event.user.name": "ann",
+			".event.tags[1]":   "eu",
+			".event.type":      "login",
+		},
+	})
+
+	// selectors within other selectors.
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".event.user", ".event.user.id", ""},
+		val:       newEventDoc(),
+		expected: map[string]interface{}{
+			".event.user":    map[string]interface{}{"id": 123, "name": "ann", "email": nil},
+			".event.user.id": 123,
+			"":               newEventDoc(),
+		},
+	})
+
+	// only values that exist are resolved.
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".event.user.email", ".event.user.phone", ".event.tags[5]", ".event.type.name"},
+		val:       newEventDoc(),
+		expected: map[string]interface{}{
+			".event.user.email": nil,
+		},
+	})
+
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".event.user.id", ".event.user.phone"},
+		opts:      []ResolveOption{Strict()},
+		val:       newEventDoc(),
+		err: ResolveError{
+			Code: "KeyError",
+			Msg:  "key 'phone' not found in '.event.user'",
+			Pos:  11,
+			Path: ".event.user",
+		},
+	})
+
+	// suggestions are only built for the error that is returned.
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".event.user.nmae"},
+		opts:      []ResolveOption{Strict()},
+		val:       newEventDoc(),
+		err: ResolveError{
+			Code:        "KeyError",
+			Msg:         "key 'nmae' not found in '.event.user'; did you mean 'name'?",
+			Pos:         11,
+			Path:        ".event.user",
+			Suggestions: []string{"name"},
+		},
+	})
+
+	runSelectorSetTest(t, selectorSetTestFixture{
+		selectors: []string{".Event.User.ID"},
+		opts:      []ResolveOption{CaseInsensitive()},
+		val:       newEventDoc(),
+		expected: map[string]interface{}{
+			".Event.User.ID": 123,
+		},
+	})
+}
+
+func TestSelectorSetResolveAll_skipSuggestions(t *testing.T) {
+	sel := mustParseAll(t, ".nmae")[0]
+	r := sel.tree.Resolver.(*MapEntryResolver)
+
+	_, err := r.resolve(map[string]interface{}{"name": "ann"}, &resolveOptions{strict: true, skipSuggestions: true})
+	expected := ResolveError{Code: "KeyError", Msg: "key 'nmae' not found", Pos: 0}
+	if diff := cmp.Diff(expected, err); diff != "" {
+		t.Errorf("error for a missing key was not as expected:\n%s", diff)
+	}
+}
+
+func TestSelectorSetResolveAll_duplicates(t *testing.T) {
+	sels := mustParseAll(t, ".event.type", "event['type']")
+	set, err := NewSelectorSet(sels...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	values, err := set.ResolveAll(newEventDoc())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(values) != 2 || values[sels[0]] != "login" || values[sels[1]] != "login" {
+		t.Errorf("unexpected values: %v", values)
+	}
+}
+
+func TestNewSelectorSet_wildcard(t *testing.T) {
+	_, err := NewSelectorSet(mustParseAll(t, ".event.tags[*]")...)
+	if err == nil || err.Error() != "cannot add '.event.tags[*]' to a selector set: wildcards match several values" {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestSelectorSetResolveJSON(t *testing.T) {
+	stream := `
+{"event": {"type": "login", "user": {"id": 123, "name": "ann"}, "tags": ["web", "eu"], "payload": {"big": [1, 2, {"x": []}]}}}
+{"event": {"type": "logout", "user": {"id": 456, "email": null}, "tags": "none"}}
+[1, 2]
+`
+	set, err := NewSelectorSet(mustParseAll(t, ".event.user.id", ".event.user.email", ".event.tags[1]", ".event.user", ".event.type")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := []map[string]interface{}{
+		{
+			".event.user.id": float64(123),
+			".event.tags[1]": "eu",
+			".event.user":    map[string]interface{}{"id": float64(123), "name": "ann"},
+			".event.type":    "login",
+		},
+		{
+			".event.user.id":    float64(456),
+			".event.user.email": nil,
+			".event.user":       map[string]interface{}{"id": float64(456), "email": nil},
+			".event.type":       "logout",
+		},
+		{},
+	}
+
+	dec := json.NewDecoder(strings.NewReader(stream))
+	for i, exp := range expected {
+		values, err := set.ResolveJSON(dec)
+		if err != nil {
+			t.Fatalf("unexpected error resolving value %d: %s", i, err)
+		}
+		if diff := cmp.Diff(exp, valuesBySelector(values)); diff != "" {
+			t.Errorf("value %d was not resolved as expected:\n%s", i, diff)
+		}
+	}
+
+	if _, err := set.ResolveJSON(dec); !errors.Is(err, io.EOF) {
+		t.Errorf("expected io.EOF at the end of the stream but got: %v", err)
+	}
+}
+
+func TestSelectorSetResolveJSON_invalid(t *testing.T) {
+	set, err := NewSelectorSet(mustParseAll(t, ".a.b")...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dec := json.NewDecoder(strings.NewReader(`{"x": [1, 2}`))
+	if _, err := set.ResolveJSON(dec); err == nil {
+		t.Errorf("expected an error resolving invalid JSON")
+	}
+}
@@ -0,0 +1,124 @@
+package selectr
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// maxSuggestions is the maximum number of keys suggested for a missing key.
+const maxSuggestions = 3
+
+// errKeyNotFound returns an error signaling that the key does not exist in
+// the container. The closest keys of the container are suggested.
+func errKeyNotFound(key interface{}, pos int, container interface{}) ResolveError {
+	var candidates []string
+	for _, e := range entries(container) {
+		candidates = append(candidates, fmt.Sprint(e.key))
+	}
+
+	suggestions := suggestKeys(fmt.Sprint(key), candidates)
+	return ResolveError{
+		Code:        "KeyError",
+		Msg:         keyNotFoundMsg(key, "", suggestions),
+		Pos:         pos,
+		Suggestions: suggestions,
+	}
+}
+
+// keyNotFoundMsg returns the message of a KeyError for the key missing at
+// the path.
+func keyNotFoundMsg(key interface{}, path string, suggestions []string) string {
+	msg := fmt.Sprintf("key '%v' not found", key)
+	if path != "" {
+		msg += fmt.Sprintf(" in '%s'", path)
+	}
+
+	switch len(suggestions) {
+	case 0:
+		return msg
+	case 1:
+		return fmt.Sprintf("%s; did you mean '%s'?", msg, suggestions[0])
+	}
+	return fmt.Sprintf("%s; did you mean one of '%s'?", msg, strings.Join(suggestions, "', '"))
+}
+
+// suggestKeys returns up to maxSuggestions candidates closest to the key.
+// Candidates matching the key case-insensitively rank first, followed by
+// candidates ranked by their edit distance to the key. Candidates that are
+// too far from the key to be a plausible typo are omitted.
+func suggestKeys(key string, candidates []string) []string {
+	type ranked struct {
+		key  string
+		dist int
+	}
+
+	lower := strings.ToLower(key)
+	maxDist := utf8.RuneCountInString(key)/3 + 1
+
+	var rs []ranked
+	for _, c := range candidates {
+		if c == key {
+			continue
+		}
+
+		dist := levenshtein(lower, strings.ToLower(c))
+		if dist == 0 {
+			// case-insensitive matches rank before any typo.
+			dist = -1
+		} else if dist > maxDist {
+			continue
+		}
+		rs = append(rs, ranked{key: c, dist: dist})
+	}
+
+	sort.Slice(rs, func(i, j int) bool {
+		if rs[i].dist != rs[j].dist {
+			return rs[i].dist < rs[j].dist
+		}
+		return rs[i].key < rs[j].key
+	})
+
+	var suggestions []string
+	for i := 0; i < len(rs) && i < maxSuggestions; i++ {
+		suggestions = append(suggestions, rs[i].key)
+	}
+	return suggestions
+}
+
+// levenshtein returns the edit distance between a and b: the number of
+// single rune insertions, deletions and substitutions to turn a into b.
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min3(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
+
+// min3 returns the smallest of three integers.
+func min3(a, b, c int) int {
+	if b < a {
+		a = b
+	}
+	if c < a {
+		a = c
+	}
+	return a
+}
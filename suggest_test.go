@@ -0,0 +1,40 @@
+package selectr
+
+import (
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLevenshtein(t *testing.T) {
+	for _, fixture := range []struct {
+		a, b     string
+		expected int
+	}{
+		{"", "", 0},
+		{"abc", "", 3},
+		{"accounts", "acounts", 1},
+		{"kitten", "sitting", 3},
+		{"héllo", "hello", 1},
+	} {
+		if dist := levenshtein(fixture.a, fixture.b); dist != fixture.expected {
+			t.Errorf("expected distance between '%s' and '%s' to be %d but got %d", fixture.a, fixture.b, fixture.expected, dist)
+		}
+	}
+}
+
+func TestSuggestKeys(t *testing.T) {
+	candidates := []string{"accounts", "Accounts", "account", "active", "settings"}
+
+	if diff := cmp.Diff([]string{"Accounts", "accounts", "account"}, suggestKeys("acounts", candidates)); diff != "" {
+		t.Errorf("suggestions for 'acounts' were not as expected:\n%s", diff)
+	}
+
+	if diff := cmp.Diff([]string{"Accounts", "account"}, suggestKeys("accounts", candidates)); diff != "" {
+		t.Errorf("suggestions for 'accounts' were not as expected:\n%s", diff)
+	}
+
+	if suggestions := suggestKeys("zzz", candidates); suggestions != nil {
+		t.Errorf("expected no suggestions for 'zzz' but got %v", suggestions)
+	}
+}
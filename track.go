@@ -0,0 +1,138 @@
+package selectr
+
+import (
+	"strings"
+	"sync"
+)
+
+// Tracker wraps a document to record the paths read from it. Values read
+// by passing the Tracker as the document to Selector.Resolve, Get and
+// Decode, or with the Resolve and Decode methods of the Tracker, have their
+// paths recorded. Other functions, such as Expand, Set and Walk, do not
+// accept a Tracker. It is safe for concurrent use.
+//
+// Example usage:
+//
+//	t := Track(config)
+//	Decode(t, &cfg)
+//	for _, path := range t.Unused() {
+//		log.Printf("warning: setting %s is ignored", path)
+//	}
+type Tracker struct {
+	doc interface{}
+
+	mu    sync.Mutex
+	paths []*Selector
+
+	// read holds the joined segments of the paths read, and prefixes those
+	// of the values containing them.
+	read     map[string]bool
+	prefixes map[string]bool
+}
+
+// Track returns a Tracker wrapping the document.
+func Track(doc interface{}) *Tracker {
+	return &Tracker{
+		doc:      doc,
+		read:     map[string]bool{},
+		prefixes: map[string]bool{},
+	}
+}
+
+// Resolve resolves the selector on the document like Selector.Resolve,
+// recording its path if it resolves to a value of the document. It is the
+// same as resolving the selector on the Tracker.
+func (t *Tracker) Resolve(sel *Selector, opts ...ResolveOption) (interface{}, error) {
+	v, err := sel.Resolve(t.doc, opts...)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		// missing keys resolve to nil unless in strict mode, but do not
+		// touch anything.
+		if _, err := sel.Resolve(t.doc, append(opts[:len(opts):len(opts)], Strict())...); err != nil {
+			return v, nil
+		}
+	}
+
+	t.record(sel.keys())
+	return v, nil
+}
+
+// Decode decodes the document into the struct pointed to by dst like
+// Decode, recording the path of every value decoded into a field. Nested
+// structs are recorded field by field, so the keys of a nested value no
+// field reads are reported as unused.
+func (t *Tracker) Decode(dst interface{}, opts ...ResolveOption) error {
+	return decode(t.doc, dst, opts, t.record)
+}
+
+// GetTracked resolves the value at the selector from the document wrapped
+// by the Tracker and converts it to T like Get, recording its path like
+// (*Tracker).Resolve. It is the same as Get with the Tracker as document.
+func GetTracked[T any](t *Tracker, sel *Selector, opts ...ResolveOption) (T, error) {
+	v, err := t.Resolve(sel, opts...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return convertTo[T](sel, v)
+}
+
+// record records the path of keys as read.
+func (t *Tracker) record(keys []interface{}) {
+	segments := keySegments(keys)
+	key := strings.Join(segments, "")
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if !t.read[key] {
+		t.read[key] = true
+		t.paths = append(t.paths, selectorFromKeys(keys))
+		for i := range segments {
+			t.prefixes[strings.Join(segments[:i], "")] = true
+		}
+	}
+}
+
+// Paths returns the canonical selectors of the paths resolved so far, in
+// the order they were first resolved.
+func (t *Tracker) Paths() []*Selector {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]*Selector(nil), t.paths...)
+}
+
+// Unused returns the canonical selectors of the values of the document no
+// resolved path has touched, in the order of Walk. A value is touched if
+// its path or the path of a value containing it was resolved, as the value
+// was resolved along with it. Only the outermost untouched values are
+// returned, e.g. `.legacy` rather than each key of an unused `.legacy` map.
+//
+// Paths are compared step by step, so keys that were only matched by the
+// CaseInsensitive or NormalizeKeys options are reported as unused.
+func (t *Tracker) Unused() []*Selector {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	var unused []*Selector
+	Walk(t.doc, func(sel *Selector, v interface{}) error {
+		segments := sel.segments()
+		for i := 0; i <= len(segments); i++ {
+			if t.read[strings.Join(segments[:i], "")] {
+				// the value was resolved along with the value containing
+				// it.
+				return SkipChildren
+			}
+		}
+
+		if len(segments) == 0 || t.prefixes[strings.Join(segments, "")] {
+			// values within the value were resolved.
+			return nil
+		}
+
+		unused = append(unused, sel)
+		return SkipChildren
+	}, AllNodes())
+	return unused
+}
@@ -0,0 +1,192 @@
+package selectr
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func newConfigDoc() map[string]interface{} {
+	return map[string]interface{}{
+		"server": map[string]interface{}{
+			"host": "localhost",
+			"port": 8080,
+			"tls":  map[string]interface{}{"cert": "a.pem", "key": "a.key"},
+		},
+		"database": map[string]interface{}{"url": "postgres://", "pool": 5},
+		"legacy":   map[string]interface{}{"mode": "old"},
+		"workers":  []interface{}{map[string]interface{}{"name": "a"}, map[string]interface{}{"name": "b", "debug": true}},
+	}
+}
+
+// selectorStrings returns the canonical selectors as strings.
+func selectorStrings(sels []*Selector) []string {
+	var s []string
+	for _, sel := range sels {
+		s = append(s, sel.String())
+	}
+	return s
+}
+
+type trackerTestFixture struct {
+	read     []string
+	expected []string
+}
+
+func runTrackerTest(t *testing.T, fixture trackerTestFixture) {
+	t.Helper()
+
+	tracker := Track(newConfigDoc())
+	for _, sel := range mustParseAll(t, fixture.read...) {
+		if _, err := tracker.Resolve(sel); err != nil {
+			t.Errorf("unexpected error resolving `%s`: %s", sel, err)
+		}
+	}
+
+	if diff := cmp.Diff(fixture.expected, selectorStrings(tracker.Unused())); diff != "" {
+		t.Errorf("unexpected unused paths after reading %v:\n%s", fixture.read, diff)
+	}
+}
+
+func TestTrackerUnused(t *testing.T) {
+	runTrackerTest(t, trackerTestFixture{
+		read: []string{".server.host", ".server.tls", "database['url']", ".workers[1].name"},
+		expected: []string{
+			".database.pool",
+			".legacy",
+			".server.port",
+			".workers[0]",
+			".workers[1].debug",
+		},
+	})
+
+	runTrackerTest(t, trackerTestFixture{
+		read:     nil,
+		expected: []string{".database", ".legacy", ".server", ".workers"},
+	})
+
+	runTrackerTest(t, trackerTestFixture{
+		read:     []string{""},
+		expected: nil,
+	})
+
+	// paths that are not in the document do not touch anything.
+	runTrackerTest(t, trackerTestFixture{
+		read:     []string{".server.missing", ".database", ".legacy", ".workers"},
+		expected: []string{".server"},
+	})
+}
+
+func TestTracker_decode(t *testing.T) {
+	type config struct {
+		Host    string `selectr:".server.host"`
+		Port    int    `selectr:".server.port"`
+		Timeout string `selectr:".server.timeout,default=5s"`
+		DB      struct {
+			URL string `selectr:".url"`
+		} `selectr:".database"`
+	}
+
+	tracker := Track(newConfigDoc())
+	var cfg config
+	if err := tracker.Decode(&cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	if cfg.Host != "localhost" || cfg.Port != 8080 || cfg.DB.URL != "postgres://" {
+		t.Errorf("unexpected config: %+v", cfg)
+	}
+
+	// the keys of nested values no field reads are unused.
+	expected := []string{".database.pool", ".legacy", ".server.tls", ".workers"}
+	if diff := cmp.Diff(expected, selectorStrings(tracker.Unused())); diff != "" {
+		t.Errorf("unexpected unused paths:\n%s", diff)
+	}
+
+	expected = []string{".server.host", ".server.port", ".database.url"}
+	if diff := cmp.Diff(expected, selectorStrings(tracker.Paths())); diff != "" {
+		t.Errorf("unexpected paths:\n%s", diff)
+	}
+}
+
+func TestTracker_decodeSlice(t *testing.T) {
+	type config struct {
+		Workers []struct {
+			Name string `selectr:".name"`
+		} `selectr:".workers"`
+	}
+
+	doc := newConfigDoc()
+	doc["server"] = nil
+	tracker := Track(doc)
+	var cfg struct {
+		config
+		Server map[string]interface{} `selectr:".server"`
+	}
+	if err := tracker.Decode(&cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// elements are recorded field by field, and nulls are read.
+	expected := []string{".database", ".legacy", ".workers[1].debug"}
+	if diff := cmp.Diff(expected, selectorStrings(tracker.Unused())); diff != "" {
+		t.Errorf("unexpected unused paths:\n%s", diff)
+	}
+}
+
+func TestTracker_get(t *testing.T) {
+	tracker := Track(newConfigDoc())
+	port, err := GetTracked[int](tracker, mustParseAll(t, ".server.port")[0])
+	if err != nil || port != 8080 {
+		t.Errorf("unexpected port: %d (%v)", port, err)
+	}
+	if diff := cmp.Diff([]string{".server.port"}, selectorStrings(tracker.Paths())); diff != "" {
+		t.Errorf("unexpected paths:\n%s", diff)
+	}
+}
+
+func TestTracker_entryPoints(t *testing.T) {
+	// passing the Tracker as the document records the paths read.
+	tracker := Track(newConfigDoc())
+	sels := mustParseAll(t, ".server.host", ".server.port", ".legacy")
+
+	if v, err := sels[0].Resolve(tracker); err != nil || v != "localhost" {
+		t.Errorf("unexpected host: %v (%v)", v, err)
+	}
+	if port, err := Get[int](sels[1], tracker); err != nil || port != 8080 {
+		t.Errorf("unexpected port: %d (%v)", port, err)
+	}
+
+	var cfg struct {
+		Legacy map[string]interface{} `selectr:".legacy"`
+	}
+	if err := Decode(tracker, &cfg); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []string{".server.host", ".server.port", ".legacy"}
+	if diff := cmp.Diff(expected, selectorStrings(tracker.Paths())); diff != "" {
+		t.Errorf("unexpected paths:\n%s", diff)
+	}
+}
+
+func TestTracker_concurrent(t *testing.T) {
+	tracker := Track(newConfigDoc())
+	sels := mustParseAll(t, ".server.host", ".server.port", ".database.url", ".legacy.mode")
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for _, sel := range sels {
+				tracker.Resolve(sel)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if n := len(tracker.Paths()); n != len(sels) {
+		t.Errorf("expected %d paths but got %d", len(sels), n)
+	}
+}
@@ -0,0 +1,146 @@
+package selectr
+
+import (
+	"errors"
+	"reflect"
+
+	"gopkg.in/yaml.v3"
+)
+
+// SkipChildren can be returned by a WalkFunc visiting a map, slice or
+// struct to skip its entries. It is not returned by Walk.
+var SkipChildren = errors.New("skip children")
+
+// WalkFunc is called by Walk for each value visited with the canonical
+// selector that resolves to it. Walk stops and returns the error if it
+// returns an error other than SkipChildren.
+type WalkFunc func(sel *Selector, v interface{}) error
+
+// WalkOption configures how a document is walked.
+type WalkOption func(*walkOptions)
+
+// walkOptions holds the configuration of a walk.
+type walkOptions struct {
+	all bool
+
+	// maxDepth is the depth below which values are not visited, or -1 if
+	// there is no limit.
+	maxDepth int
+}
+
+// newWalkOptions applies the options to the default configuration.
+func newWalkOptions(opts []WalkOption) *walkOptions {
+	o := &walkOptions{maxDepth: -1}
+	for _, opt := range opts {
+		opt(o)
+	}
+	return o
+}
+
+// AllNodes visits the maps, slices and structs of the document before
+// their entries, rather than only the leaves.
+func AllNodes() WalkOption {
+	return func(o *walkOptions) {
+		o.all = true
+	}
+}
+
+// MaxDepth limits the walk to values at most depth segments deep. Values at
+// the limit are visited as leaves. The root of the document is at depth 0.
+func MaxDepth(depth int) WalkOption {
+	return func(o *walkOptions) {
+		o.maxDepth = depth
+	}
+}
+
+// Walk visits the leaves of the document in a deterministic order, depth
+// first, calling fn with a canonical selector that resolves back to each.
+// Map keys are visited sorted, with integer keys first, and slice elements
+// in order. Leaves are scalars, empty containers and values at the
+// MaxDepth limit. YAML aliases are followed, except to a node that
+// contains the alias, which is visited as a leaf. With AllNodes, every value is visited, including the
+// root with an empty selector.
+//
+// The same containers as Selector.Resolve are walked: maps, slices, yaml.v3
+// nodes and, by reflection, typed maps and slices and structs.
+//
+// Example usage:
+//
+//	Walk(map[string]interface{}{"a": []interface{}{1, 2}}, func(sel *Selector, v interface{}) error {
+//		fmt.Println(sel, v)
+//		return nil
+//	})
+//	// .a[0] 1
+//	// .a[1] 2
+func Walk(doc interface{}, fn WalkFunc, opts ...WalkOption) error {
+	o := newWalkOptions(opts)
+
+	err := walk(nil, doc, fn, o, map[*yaml.Node]bool{})
+	if err == SkipChildren {
+		return nil
+	}
+	return err
+}
+
+// Paths returns the canonical selectors of the values visited by Walk, in
+// the same order.
+func Paths(doc interface{}, opts ...WalkOption) []*Selector {
+	var paths []*Selector
+	Walk(doc, func(sel *Selector, v interface{}) error {
+		paths = append(paths, sel)
+		return nil
+	}, opts...)
+	return paths
+}
+
+// walk walks the value at the path of keys. ancestors holds the YAML
+// nodes on the path, so that recursive aliases are not followed forever.
+func walk(keys []interface{}, v interface{}, fn WalkFunc, o *walkOptions, ancestors map[*yaml.Node]bool) error {
+	var es []entry
+	if isContainer(v) && (o.maxDepth < 0 || len(keys) < o.maxDepth) {
+		if n, ok := v.(*yaml.Node); ok {
+			n = derefYAMLNode(n)
+			if !ancestors[n] {
+				ancestors[n] = true
+				defer delete(ancestors, n)
+				es = entries(v)
+			}
+		} else {
+			es = entries(v)
+		}
+	}
+
+	if len(es) == 0 || o.all {
+		if err := fn(selectorFromKeys(keys), v); err != nil {
+			return err
+		}
+	}
+
+	for _, e := range es {
+		err := walk(appendKey(keys, e.key), e.val, fn, o, ancestors)
+		if err != nil && err != SkipChildren {
+			return err
+		}
+	}
+	return nil
+}
+
+// isContainer determines if the value has entries that can be selected:
+// maps, slices, arrays, structs and yaml.v3 mapping and sequence nodes.
+func isContainer(v interface{}) bool {
+	if n, ok := v.(*yaml.Node); ok {
+		n = derefYAMLNode(n)
+		return n != nil && (n.Kind == yaml.MappingNode || n.Kind == yaml.SequenceNode)
+	}
+
+	switch indirect(reflect.ValueOf(v)).Kind() {
+	case reflect.Map, reflect.Slice, reflect.Array, reflect.Struct:
+		return true
+	}
+	return false
+}
+
+// appendKey returns a copy of the keys with the key appended.
+func appendKey(keys []interface{}, key interface{}) []interface{} {
+	return append(keys[:len(keys):len(keys)], key)
+}
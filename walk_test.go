@@ -0,0 +1,204 @@
+package selectr
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"gopkg.in/yaml.v3"
+)
+
+type walkTestFixture struct {
+	val      interface{}
+	opts     []WalkOption
+	expected []string
+}
+
+func runWalkTest(t *testing.T, fixture walkTestFixture) {
+	t.Helper()
+
+	var paths []string
+	err := Walk(fixture.val, func(sel *Selector, v interface{}) error {
+		paths = append(paths, sel.String())
+
+		// each selector resolves back to the value.
+		resolved, err := sel.Resolve(fixture.val)
+		if err != nil {
+			t.Errorf("`%s` could not be resolved: %s", sel, err)
+		} else if diff := cmp.Diff(v, resolved); diff != "" {
+			t.Errorf("`%s` did not resolve to the value visited:\n%s", sel, diff)
+		}
+		return nil
+	}, fixture.opts...)
+	if err != nil {
+		t.Errorf("unexpected error walking: %s", err)
+	}
+
+	if diff := cmp.Diff(fixture.expected, paths); diff != "" {
+		t.Errorf("paths were not walked as expected:\n%s", diff)
+	}
+}
+
+func TestWalk(t *testing.T) {
+	val := map[string]interface{}{
+		"accounts": []interface{}{
+			map[string]interface{}{"name": "main", "id": 1},
+			map[string]interface{}{"name": "backup", "tags": []interface{}{}},
+		},
+		"content-type": "json",
+		"labels":       map[interface{}]interface{}{2: "two", "x": "y", true: "yes"},
+	}
+
+	runWalkTest(t, walkTestFixture{
+		val: val,
+		expected: []string{
+			".accounts[0].id",
+			".accounts[0].name",
+			".accounts[1].name",
+			".accounts[1].tags",
+			"['content-type']",
+			".labels[2]",
+			".labels.true",
+			".labels.x",
+		},
+	})
+
+	runWalkTest(t, walkTestFixture{
+		val:  val,
+		opts: []WalkOption{AllNodes(), MaxDepth(2)},
+		expected: []string{
+			"",
+			".accounts",
+			".accounts[0]",
+			".accounts[1]",
+			"['content-type']",
+			".labels",
+			".labels[2]",
+			".labels.true",
+			".labels.x",
+		},
+	})
+
+	runWalkTest(t, walkTestFixture{
+		val:      val,
+		opts:     []WalkOption{MaxDepth(1)},
+		expected: []string{".accounts", "['content-type']", ".labels"},
+	})
+
+	runWalkTest(t, walkTestFixture{
+		val:      "scalar",
+		expected: []string{""},
+	})
+}
+
+func TestWalk_containers(t *testing.T) {
+	type account struct {
+		Name string   `json:"name"`
+		Tags []string `json:"tags"`
+	}
+
+	runWalkTest(t, walkTestFixture{
+		val: map[string][]account{
+			"accounts": {{Name: "main", Tags: []string{"a"}}},
+		},
+		expected: []string{".accounts[0].name", ".accounts[0].tags[0]"},
+	})
+
+	var doc yaml.Node
+	yaml.Unmarshal([]byte("base: &base {a: 1}\nderived:\n  <<: *base\n  b: [x, y]\n"), &doc)
+
+	runWalkTest(t, walkTestFixture{
+		val:      &doc,
+		expected: []string{".base.a", ".derived.a", ".derived.b[0]", ".derived.b[1]"},
+	})
+}
+
+func TestWalk_recursiveAlias(t *testing.T) {
+	// an alias to a node containing it is visited as a leaf.
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("a: &x\n  b: *x\n  c: 1\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	runWalkTest(t, walkTestFixture{
+		val:      &doc,
+		expected: []string{".a.b", ".a.c"},
+	})
+
+	runWalkTest(t, walkTestFixture{
+		val:      &doc,
+		opts:     []WalkOption{AllNodes()},
+		expected: []string{"", ".a", ".a.b", ".a.c"},
+	})
+
+	unused := Track(&doc).Unused()
+	if diff := cmp.Diff([]string{".a"}, selectorStrings(unused)); diff != "" {
+		t.Errorf("unexpected unused paths:\n%s", diff)
+	}
+}
+
+func TestWalk_skipChildren(t *testing.T) {
+	val := map[string]interface{}{
+		"a": map[string]interface{}{"b": 1},
+		"c": map[string]interface{}{"d": 2},
+	}
+
+	var paths []string
+	Walk(val, func(sel *Selector, v interface{}) error {
+		paths = append(paths, sel.String())
+		if sel.String() == ".a" {
+			return SkipChildren
+		}
+		return nil
+	}, AllNodes())
+
+	if diff := cmp.Diff([]string{"", ".a", ".c", ".c.d"}, paths); diff != "" {
+		t.Errorf("paths were not walked as expected:\n%s", diff)
+	}
+
+	stop := errors.New("stop")
+	err := Walk(val, func(sel *Selector, v interface{}) error {
+		return stop
+	})
+	if err != stop {
+		t.Errorf("expected Walk to return the error of the walk function but got %v", err)
+	}
+}
+
+func TestWalk_controlCharacterKeys(t *testing.T) {
+	// selectors are built from the keys themselves, so any key can be
+	// selected.
+	val := map[string]interface{}{"a\x00b": map[string]interface{}{"c": 1}}
+
+	runWalkTest(t, walkTestFixture{
+		val:      val,
+		expected: []string{`['a\0b'].c`},
+	})
+
+	changes := Diff(val, map[string]interface{}{"a\x00b": map[string]interface{}{"c": 2}})
+	if len(changes) != 1 || cmp.Diff([]interface{}{"a\x00b", "c"}, changes[0].Path.keys()) != "" {
+		t.Errorf("expected a change at the key with a NUL character but got %v", changes)
+	}
+
+	sel := Root().Key("a\x00b").Wildcard()
+	matches, err := sel.Expand(val)
+	if err != nil || len(matches) != 1 || cmp.Diff([]interface{}{"a\x00b", "c"}, matches[0].keys()) != "" {
+		t.Errorf("expected `%s` to expand to the key with a NUL character but got %v (error: %v)", sel, matches, err)
+	}
+
+	p := &Policy{Deny: []*Selector{Root().Key("a\x00b").Key("c")}}
+	if doc, err := p.Apply(val); err != nil || cmp.Diff(map[string]interface{}{"a\x00b": map[string]interface{}{}}, doc) != "" {
+		t.Errorf("expected the key with a NUL character to be denied but got %v (error: %v)", doc, err)
+	}
+}
+
+func TestPaths(t *testing.T) {
+	var paths []string
+	for _, sel := range Paths([]interface{}{1, []interface{}{2, 3}}) {
+		paths = append(paths, sel.String())
+	}
+
+	if diff := cmp.Diff([]string{"[0]", "[1][0]", "[1][1]"}, paths); diff != "" {
+		t.Errorf("paths were not as expected:\n%s", diff)
+	}
+}
@@ -0,0 +1,112 @@
+package selectr
+
+import (
+	"github.com/0xch4z/selectr/internal/parser/ast"
+)
+
+// WildcardResolver represents a wildcard, `.*` or `[*]`, matching every
+// entry of a map or struct and every element of a slice. As a wildcard
+// matches several values, it does not resolve to a value; selectors
+// containing wildcards are expanded to the selectors of the values they
+// match with Expand.
+type WildcardResolver struct {
+	Expr ast.Expr
+}
+
+// Resolve always returns an error, as a wildcard matches several values.
+func (r *WildcardResolver) Resolve(v interface{}) (interface{}, error) {
+	return nil, ResolveError{
+		Code: "TypeError",
+		Msg:  "wildcard matches several values; use Expand",
+		Pos:  r.Expr.StartPos(),
+	}
+}
+
+// Expression returns the corresponding ast.Expr.
+func (r *WildcardResolver) Expression() ast.Expr {
+	return r.Expr
+}
+
+// WildcardResolver implements Resolver.
+var _ Resolver = (*WildcardResolver)(nil)
+
+// wildcard is the step key of a wildcard.
+type wildcard struct{}
+
+// String returns the wildcard as written in a JSON Pointer or a dotted
+// path.
+func (wildcard) String() string {
+	return "*"
+}
+
+// Expand returns the canonical selectors of the values the selector matches
+// on the document, with each wildcard replaced by the keys of the map or the
+// indices of the slice it is applied to. Selectors are returned in the order
+// Walk visits the values, with sorted map keys.
+//
+// Keys and indices that do not exist are not matched, nor are wildcards
+// applied to scalars, so an empty result is returned rather than an error.
+// Selectors without wildcards expand to themselves if they resolve to a
+// value.
+//
+// Example usage:
+//
+//	sel, _ := Parse(".accounts[*].name")
+//	sel.Expand(map[string]interface{}{
+//		"accounts": []interface{}{
+//			map[string]interface{}{"name": "main"},
+//			map[string]interface{}{"name": "backup"},
+//		},
+//	})
+//	// => [.accounts[0].name .accounts[1].name]
+func (s *Selector) Expand(doc interface{}, opts ...ResolveOption) ([]*Selector, error) {
+	if s.err != nil {
+		return nil, s.err
+	}
+	o := newResolveOptions(opts)
+	o.strict = true
+
+	var matches []*Selector
+	err := expand(s.tree, doc, nil, o, &matches)
+	if err != nil {
+		return nil, err
+	}
+	return matches, nil
+}
+
+// expand recursively expands the traversal tree node on the value at the
+// path of keys, appending the selectors of the matched values.
+func expand(node *TraversalTreeNode, v interface{}, keys []interface{}, o *resolveOptions, matches *[]*Selector) error {
+	if node == nil {
+		*matches = append(*matches, selectorFromKeys(keys))
+		return nil
+	}
+
+	if _, ok := node.Resolver.(*WildcardResolver); ok {
+		if !isContainer(v) {
+			return nil
+		}
+		for _, e := range entries(v) {
+			if err := expand(node.Child, e.val, appendKey(keys, e.key), o, matches); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	var child interface{}
+	var err error
+	if r, ok := node.Resolver.(optionResolver); ok {
+		child, err = r.resolve(v, o)
+	} else {
+		child, err = node.Resolver.Resolve(v)
+	}
+	if err != nil {
+		if e, ok := err.(ResolveError); ok && (e.Code == "KeyError" || e.Code == "IndexError" || e.Code == "TypeError") {
+			// the key or index does not exist on the value.
+			return nil
+		}
+		return err
+	}
+	return expand(node.Child, child, appendKey(keys, stepKey(node.Resolver)), o, matches)
+}
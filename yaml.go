@@ -0,0 +1,314 @@
+package selectr
+
+import (
+	"strconv"
+
+	"gopkg.in/yaml.v3"
+)
+
+// yamlMergeTag is the tag yaml.v3 resolves merge keys (`<<`) to.
+const yamlMergeTag = "!!merge"
+
+// derefYAMLNode follows document and alias nodes until it reaches a node
+// holding content. nil is returned for empty documents.
+func derefYAMLNode(n *yaml.Node) *yaml.Node {
+	for n != nil {
+		switch n.Kind {
+		case yaml.DocumentNode:
+			if len(n.Content) == 0 {
+				return nil
+			}
+			n = n.Content[0]
+		case yaml.AliasNode:
+			n = n.Alias
+		default:
+			return n
+		}
+	}
+	return nil
+}
+
+// yamlKindName returns a human readable name for the kind of node.
+func yamlKindName(n *yaml.Node) string {
+	if n == nil {
+		return "empty YAML document"
+	}
+	switch n.Kind {
+	case yaml.DocumentNode:
+		return "YAML document node"
+	case yaml.SequenceNode:
+		return "YAML sequence node"
+	case yaml.MappingNode:
+		return "YAML mapping node"
+	case yaml.ScalarNode:
+		return "YAML scalar node"
+	case yaml.AliasNode:
+		return "YAML alias node"
+	}
+	return "YAML node"
+}
+
+// isYAMLMergeKey determines if the key node is a merge key (`<<`).
+func isYAMLMergeKey(k *yaml.Node) bool {
+	return k.Kind == yaml.ScalarNode && k.ShortTag() == yamlMergeTag
+}
+
+// yamlMappingLookup finds the value node for the key matching fn in the
+// mapping node. Keys declared on the mapping itself take precedence over
+// keys merged in with `<<`; when several mappings are merged, earlier
+// mappings take precedence over later ones.
+//
+// Mappings merged into themselves, directly or through other mappings, are
+// only searched once.
+func yamlMappingLookup(n *yaml.Node, fn func(k *yaml.Node) bool) (*yaml.Node, bool) {
+	return lookupYAMLMapping(n, fn, map[*yaml.Node]bool{})
+}
+
+// lookupYAMLMapping finds the value node for the key matching fn in the
+// mapping node, skipping the mappings already visited.
+func lookupYAMLMapping(n *yaml.Node, fn func(k *yaml.Node) bool, visited map[*yaml.Node]bool) (*yaml.Node, bool) {
+	if visited[n] {
+		return nil, false
+	}
+	visited[n] = true
+
+	var merged []*yaml.Node
+
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := derefYAMLNode(n.Content[i])
+		if k == nil {
+			continue
+		}
+		if isYAMLMergeKey(k) {
+			merged = append(merged, n.Content[i+1])
+			continue
+		}
+		if fn(k) {
+			return derefYAMLNode(n.Content[i+1]), true
+		}
+	}
+
+	for _, m := range merged {
+		m = derefYAMLNode(m)
+		if m == nil {
+			continue
+		}
+
+		// a merge key may reference a single mapping or a sequence of
+		// mappings.
+		sources := []*yaml.Node{m}
+		if m.Kind == yaml.SequenceNode {
+			sources = m.Content
+		}
+
+		for _, src := range sources {
+			if src = derefYAMLNode(src); src == nil || src.Kind != yaml.MappingNode {
+				continue
+			}
+			if v, ok := lookupYAMLMapping(src, fn, visited); ok {
+				return v, true
+			}
+		}
+	}
+
+	return nil, false
+}
+
+// yamlKeyMatcher returns a function matching scalar key nodes whose value
+// is the key.
+func yamlKeyMatcher(key string) func(*yaml.Node) bool {
+	return func(k *yaml.Node) bool {
+		return k.Kind == yaml.ScalarNode && k.Value == key
+	}
+}
+
+// yamlIntKeyMatcher returns a function matching integer key nodes whose
+// value is the index.
+func yamlIntKeyMatcher(index int) func(*yaml.Node) bool {
+	return func(k *yaml.Node) bool {
+		if k.Kind != yaml.ScalarNode || k.ShortTag() != "!!int" {
+			return false
+		}
+		n, err := strconv.Atoi(k.Value)
+		return err == nil && n == index
+	}
+}
+
+// ResolveNode resolves the node at the specified key-path from a yaml.v3
+// node tree. Unlike Resolve, the returned node retains its position in the
+// source document (see (*yaml.Node).Line and (*yaml.Node).Column).
+//
+// Aliases are followed and keys merged with `<<` are honored. A nil node
+// is returned if the key-path does not exist.
+func (s *Selector) ResolveNode(n *yaml.Node, opts ...ResolveOption) (*yaml.Node, error) {
+	v, err := s.Resolve(n, opts...)
+	if err != nil {
+		return nil, err
+	}
+	node, _ := v.(*yaml.Node)
+	if s.tree == nil {
+		// an empty selector references the content of the document rather
+		// than the document itself.
+		node = derefYAMLNode(node)
+	}
+	return node, nil
+}
+
+// yamlMappingIndex returns the index of the key node matching fn that is
+// declared on the mapping node itself, or -1 if there is none. Keys merged
+// in with `<<` are not considered.
+func yamlMappingIndex(n *yaml.Node, fn func(k *yaml.Node) bool) int {
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		if k := derefYAMLNode(n.Content[i]); k != nil && !isYAMLMergeKey(k) && fn(k) {
+			return i
+		}
+	}
+	return -1
+}
+
+// replaceYAMLNode replaces the i-th content node of the parent. Comments
+// attached to the previous node are carried over to the new one. If the
+// previous node is, or is an alias to, the new node, it is left untouched.
+func replaceYAMLNode(parent *yaml.Node, i int, n *yaml.Node) {
+	prev := parent.Content[i]
+	if prev == n || derefYAMLNode(prev) == n {
+		return
+	}
+
+	if n.HeadComment == "" {
+		n.HeadComment = prev.HeadComment
+	}
+	if n.LineComment == "" {
+		n.LineComment = prev.LineComment
+	}
+	if n.FootComment == "" {
+		n.FootComment = prev.FootComment
+	}
+	if prev.Kind == yaml.AliasNode && n.Anchor == prev.Value {
+		// a copy of the anchored node replacing an alias to it must not
+		// redefine the anchor.
+		n.Anchor = ""
+	}
+	parent.Content[i] = n
+}
+
+// yamlChildShared determines if the child the resolver resolved from the
+// object is a node shared with another part of the document: one reached
+// through an alias, or through a key merged in with `<<`, rather than held
+// by the object itself. Modifying it in place would modify the anchored
+// node.
+func yamlChildShared(r Resolver, obj, child interface{}) bool {
+	n, ok := obj.(*yaml.Node)
+	if !ok {
+		return false
+	}
+	c, ok := child.(*yaml.Node)
+	if !ok || c == nil {
+		return false
+	}
+	if n = derefYAMLNode(n); n == nil {
+		return false
+	}
+
+	i := -1
+	switch r := r.(type) {
+	case *MapEntryResolver:
+		if n.Kind == yaml.MappingNode {
+			if i = yamlMappingIndex(n, yamlKeyMatcher(r.Key)); i != -1 {
+				i++
+			}
+		}
+	case *SliceElementResolver:
+		switch n.Kind {
+		case yaml.SequenceNode:
+			if r.Index < len(n.Content) {
+				i = r.Index
+			}
+		case yaml.MappingNode:
+			if i = yamlMappingIndex(n, yamlIntKeyMatcher(r.Index)); i != -1 {
+				i++
+			}
+		}
+	}
+
+	// the child was merged in if it is not declared on the object.
+	return i == -1 || n.Content[i] != c
+}
+
+// unanchorYAMLNode removes the anchor from a copy of a shared node, so it
+// does not redefine the anchor of the node it was copied from.
+func unanchorYAMLNode(v interface{}) {
+	if n, ok := v.(*yaml.Node); ok && n != nil {
+		n.Anchor = ""
+	}
+}
+
+// newYAMLStringNode returns a new scalar node holding the string.
+func newYAMLStringNode(s string) *yaml.Node {
+	n := &yaml.Node{}
+	n.SetString(s)
+	return n
+}
+
+// toYAMLNode encodes the value as a node. Nodes are returned as-is.
+func toYAMLNode(v interface{}) (*yaml.Node, error) {
+	if n, ok := v.(*yaml.Node); ok && n != nil {
+		return n, nil
+	}
+
+	n := &yaml.Node{}
+	if err := n.Encode(v); err != nil {
+		return nil, err
+	}
+	return n, nil
+}
+
+// yamlMappingKeys returns the scalar key nodes of the mapping node,
+// including keys merged in with `<<`. Keys are returned once, in the order
+// they take precedence in. Mappings merged into themselves, directly or
+// through other mappings, are only visited once.
+func yamlMappingKeys(n *yaml.Node) []*yaml.Node {
+	var keys []*yaml.Node
+	collectYAMLMappingKeys(n, &keys, map[string]bool{}, map[*yaml.Node]bool{})
+	return keys
+}
+
+// collectYAMLMappingKeys appends the keys of the mapping node not seen yet
+// to keys, skipping the mappings already visited.
+func collectYAMLMappingKeys(n *yaml.Node, keys *[]*yaml.Node, seen map[string]bool, visited map[*yaml.Node]bool) {
+	if visited[n] {
+		return
+	}
+	visited[n] = true
+
+	var merged []*yaml.Node
+	for i := 0; i+1 < len(n.Content); i += 2 {
+		k := derefYAMLNode(n.Content[i])
+		if k == nil || k.Kind != yaml.ScalarNode {
+			continue
+		}
+		if isYAMLMergeKey(k) {
+			merged = append(merged, n.Content[i+1])
+			continue
+		}
+		if id := k.ShortTag() + ":" + k.Value; !seen[id] {
+			seen[id] = true
+			*keys = append(*keys, k)
+		}
+	}
+
+	for _, m := range merged {
+		if m = derefYAMLNode(m); m == nil {
+			continue
+		}
+		sources := []*yaml.Node{m}
+		if m.Kind == yaml.SequenceNode {
+			sources = m.Content
+		}
+		for _, src := range sources {
+			if src = derefYAMLNode(src); src != nil && src.Kind == yaml.MappingNode {
+				collectYAMLMappingKeys(src, keys, seen, visited)
+			}
+		}
+	}
+}
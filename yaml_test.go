@@ -0,0 +1,182 @@
+package selectr
+
+import (
+	"regexp"
+	"testing"
+
+	"gopkg.in/yaml.v3"
+)
+
+const yamlTestDocument = `defaults: &defaults
+  adapter: postgres
+  host: localhost
+
+development:
+  <<: *defaults
+  database: dev
+
+test:
+  <<: [*defaults, {port: 5432}]
+  host: test.local
+
+accounts:
+  - id: 123
+    name: main
+  - &other
+    id: 456
+    name: other
+
+alias: *other
+`
+
+type yamlNodeTestFixture struct {
+	selector string
+	errRegex *regexp.Regexp
+	value    string
+	line     int
+	column   int
+	isNil    bool
+}
+
+func runYAMLNodeTest(t *testing.T, doc *yaml.Node, fixture yamlNodeTestFixture) {
+	t.Helper()
+
+	sel, err := Parse(fixture.selector)
+	if err != nil {
+		t.Errorf("could not parse selector `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	node, err := sel.ResolveNode(doc)
+	if fixture.errRegex != nil {
+		if err == nil {
+			t.Error("expected error to match regex but none was thrown")
+		} else if !fixture.errRegex.MatchString(err.Error()) {
+			t.Errorf("expected error to match pattern '%s' but got '%s'", fixture.errRegex, err)
+		}
+		return
+	} else if err != nil {
+		t.Errorf("unexpected error resolving `%s`: %s", fixture.selector, err)
+		return
+	}
+
+	if fixture.isNil {
+		if node != nil {
+			t.Errorf("expected `%s` to resolve to a nil node but got %v", fixture.selector, node)
+		}
+		return
+	}
+
+	if node == nil {
+		t.Errorf("`%s` unexpectedly resolved to a nil node", fixture.selector)
+		return
+	}
+
+	if node.Value != fixture.value || node.Line != fixture.line || node.Column != fixture.column {
+		t.Errorf("`%s` resolved to %q at %d:%d; expected %q at %d:%d", fixture.selector,
+			node.Value, node.Line, node.Column, fixture.value, fixture.line, fixture.column)
+	}
+}
+
+func TestResolveNode(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlTestDocument), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".accounts[0].name",
+		value:    "main",
+		line:     15,
+		column:   11,
+	})
+
+	// merged keys resolve to the node they were declared at.
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".development.adapter",
+		value:    "postgres",
+		line:     2,
+		column:   12,
+	})
+
+	// keys declared on the mapping override merged keys.
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".test.host",
+		value:    "test.local",
+		line:     11,
+		column:   9,
+	})
+
+	// sequences of merged mappings are supported.
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".test.port",
+		value:    "5432",
+		line:     10,
+		column:   26,
+	})
+
+	// aliases are followed.
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".alias.name",
+		value:    "other",
+		line:     18,
+		column:   11,
+	})
+
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".development.missing",
+		isNil:    true,
+	})
+
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".accounts.name",
+		errRegex: regexp.MustCompile("cannot resolve attribute 'name' on YAML sequence node"),
+	})
+
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{
+		selector: ".accounts[2]",
+		errRegex: regexp.MustCompile("index out of range; index is 2 but length is only 2"),
+	})
+}
+
+func TestResolve_yamlNode(t *testing.T) {
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte(yamlTestDocument), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	sel, _ := Parse(".accounts[1].id")
+	v, err := sel.Resolve(&doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var id int
+	if err := v.(*yaml.Node).Decode(&id); err != nil {
+		t.Fatal(err)
+	}
+	if id != 456 {
+		t.Errorf("expected `.accounts[1].id` to decode to 456 but got %d", id)
+	}
+}
+
+func TestResolve_yamlMergeCycle(t *testing.T) {
+	// yaml.v3 parses mappings merged into themselves, though it cannot
+	// decode them.
+	var doc yaml.Node
+	if err := yaml.Unmarshal([]byte("a: &x\n  b: 1\n  <<: *x\n"), &doc); err != nil {
+		t.Fatal(err)
+	}
+
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{selector: ".a.b", value: "1", line: 2, column: 6})
+	runYAMLNodeTest(t, &doc, yamlNodeTestFixture{selector: ".a.zz", isNil: true})
+
+	sel, _ := Parse(".a.zz")
+	if _, err := sel.Resolve(&doc, Strict()); err == nil || err.(ResolveError).Code != "KeyError" {
+		t.Errorf("expected a KeyError resolving `.a.zz` strictly but got %v", err)
+	}
+
+	if s := Complete(".a.", &doc); len(s) != 1 || s[0].Selector != ".a.b" {
+		t.Errorf("expected `.a.b` to be completed but got %v", s)
+	}
+}